@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// fieldAccess はアクセス権セットごとのフィールドのアクセス権。
+type fieldAccess struct {
+	PrivilegeSet string `json:"privilegeSet" yaml:"privilegeSet"`
+	Access       string `json:"access" yaml:"access"` // edit / view / none
+}
+
+// accessAliases はセルに書けるアクセス権の表記。
+var accessAliases = map[string]string{
+	"edit": "edit", "編集": "edit", "変更可能": "edit",
+	"view": "view", "閲覧": "view", "閲覧のみ": "view", "参照": "view",
+	"none": "none", "なし": "none", "アクセスなし": "none", "-": "none",
+}
+
+// normalizeAccess はアクセス権を edit / view / none にそろえ、アクセス権セットの重複を検出する。
+func normalizeAccess(f *field) error {
+	seen := map[string]bool{}
+	for i, a := range f.Access {
+		level, ok := accessAliases[strings.ToLower(strings.TrimSpace(a.Access))]
+		if !ok {
+			return fmt.Errorf("%s: field %q has unknown access %q for privilege set %q (edit, view or none)", f.origin, f.Name, a.Access, a.PrivilegeSet)
+		}
+		if seen[a.PrivilegeSet] {
+			return fmt.Errorf("%s: field %q has duplicate access for privilege set %q", f.origin, f.Name, a.PrivilegeSet)
+		}
+		seen[a.PrivilegeSet] = true
+		f.Access[i].Access = level
+	}
+	return nil
+}
+
+func describeAccess(access []fieldAccess) string {
+	var parts []string
+	for _, a := range access {
+		parts = append(parts, a.PrivilegeSet+": "+a.Access)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// privilegeSets はアクセス権が指定されているアクセス権セットの名前を、最初に現れた順に返す。
+func privilegeSets(s *schema) []string {
+	var names []string
+	for _, t := range s.Tables {
+		for _, f := range t.Fields {
+			for _, a := range f.Access {
+				if !slices.Contains(names, a.PrivilegeSet) {
+					names = append(names, a.PrivilegeSet)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// renderAccessReport はテーブルごとに、フィールド × アクセス権セットのアクセス権の表を Markdown で返す。
+// FileMaker のアクセス権セットはクリップボードで貼り付けられないので、カスタムのフィールドアクセス権を設定するときの一覧として使う。
+func renderAccessReport(s *schema, _ outputOptions) ([]byte, error) {
+	sets := privilegeSets(s)
+	if len(sets) == 0 {
+		return nil, errors.New("no field access is defined")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# フィールドアクセス権\n")
+	for _, t := range s.Tables {
+		fmt.Fprintf(&buf, "\n## %s\n\n", t.Name)
+		buf.WriteString("| フィールド名 |")
+		for _, set := range sets {
+			buf.WriteString(" " + markdownCellReplacer.Replace(set) + " |")
+		}
+		buf.WriteString("\n")
+		buf.WriteString(strings.Repeat("|---", len(sets)+1) + "|\n")
+		for _, f := range t.Fields {
+			cells := []string{markdownCellReplacer.Replace(f.Name)}
+			for _, set := range sets {
+				// 指定のないアクセス権セットは空欄（アクセス権セット側の既定のまま）
+				access := ""
+				if i := slices.IndexFunc(f.Access, func(a fieldAccess) bool { return a.PrivilegeSet == set }); i >= 0 {
+					access = f.Access[i].Access
+				}
+				cells = append(cells, access)
+			}
+			buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		access []fieldAccess
+		want   []fieldAccess
+		err    string
+	}{
+		{
+			name:   "aliases",
+			access: []fieldAccess{{"Staff", "編集"}, {"Guest", " 閲覧のみ "}, {"Temp", "-"}, {"Admin", "Edit"}},
+			want:   []fieldAccess{{"Staff", "edit"}, {"Guest", "view"}, {"Temp", "none"}, {"Admin", "edit"}},
+		},
+		{
+			name:   "unknown access",
+			access: []fieldAccess{{"Staff", "write"}},
+			err:    `t!A10: field "memo" has unknown access "write" for privilege set "Staff" (edit, view or none)`,
+		},
+		{
+			name:   "duplicate privilege set",
+			access: []fieldAccess{{"Staff", "edit"}, {"Staff", "view"}},
+			err:    `t!A10: field "memo" has duplicate access for privilege set "Staff"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &field{Name: "memo", Access: tt.access, origin: "t!A10"}
+			err := normalizeAccess(f)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(f.Access, tt.want) {
+				t.Errorf("access = %v, want %v", f.Access, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderAccessReport(t *testing.T) {
+	s := &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{
+		{Name: "id", Access: []fieldAccess{{"Staff", "view"}}},
+		{Name: "salary", Access: []fieldAccess{{"Guest", "none"}, {"Staff", "edit"}}},
+		{Name: "memo"},
+	}}}}
+	out, err := renderAccessReport(s, outputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# フィールドアクセス権
+
+## t
+
+| フィールド名 | Staff | Guest |
+|---|---|---|
+| id | view |  |
+| salary | edit | none |
+| memo |  |  |
+`
+	if string(out) != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+
+	// データ辞書には、アクセス権が指定されているときだけアクセス権の列を載せる
+	doc, err := renderMarkdown(s, outputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc), "| 保存 | アクセス権 |") || !strings.Contains(string(doc), "Guest: none, Staff: edit |") {
+		t.Errorf("access column not found in\n%s", doc)
+	}
+	s.Tables[0].Fields = s.Tables[0].Fields[2:]
+	if _, err := renderAccessReport(s, outputOptions{}); err == nil {
+		t.Error("access report without access succeeded")
+	}
+	if doc, _ := renderMarkdown(s, outputOptions{}); strings.Contains(string(doc), docAccessColumn) {
+		t.Errorf("access column without access settings:\n%s", doc)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FileMaker の組み込み関数（計算式の演算子 not なども含む）。大文字小文字は区別しない。
+var builtinFunctions = newNameSet(
+	// テキスト
+	"Char", "Code", "Exact", "Filter", "FilterValues", "GetAsCSS", "GetAsDate", "GetAsNumber", "GetAsSVG",
+	"GetAsText", "GetAsTime", "GetAsTimestamp", "GetAsURLEncoded", "GetValue", "Hiragana", "KanaHankaku",
+	"KanaZenkaku", "KanjiNumeral", "Katakana", "Left", "LeftValues", "LeftWords", "Length", "Lower", "Middle",
+	"MiddleValues", "MiddleWords", "NumToJText", "PatternCount", "Position", "Proper", "Quote", "Replace",
+	"Right", "RightValues", "RightWords", "RomanHankaku", "RomanZenkaku", "SerialIncrement", "SortValues",
+	"Substitute", "Trim", "TrimAll", "UniqueValues", "Upper", "ValueCount", "WordCount",
+	"RGB", "TextColor", "TextColorRemove", "TextFont", "TextFontRemove", "TextFormatRemove", "TextSize",
+	"TextSizeRemove", "TextStyleAdd", "TextStyleRemove",
+	// 数字
+	"Abs", "Ceiling", "Combination", "Div", "Exp", "Factorial", "Floor", "Int", "Lg", "Ln", "Log", "Mod",
+	"Random", "Round", "SetPrecision", "Sign", "Sqrt", "Truncate",
+	// 日付・時刻・タイムスタンプ
+	"Date", "Day", "DayName", "DayNameJ", "DayOfWeek", "DayOfYear", "Month", "MonthName", "MonthNameJ",
+	"WeekOfYear", "WeekOfYearFiscal", "Year", "YearName", "Hour", "Minute", "Seconds", "Time", "Timestamp",
+	// オブジェクト
+	"Base64Decode", "Base64Encode", "Base64EncodeRFC", "CryptAuthCode", "CryptDecrypt", "CryptDecryptBase64",
+	"CryptDigest", "CryptEncrypt", "CryptEncryptBase64", "CryptGenerateSignature", "CryptVerifySignature",
+	"GetContainerAttribute", "GetHeight", "GetLiveText", "GetLiveTextAsJSON", "GetThumbnail", "GetWidth",
+	"HexDecode", "HexEncode", "TextDecode", "TextEncode", "VerifyContainer",
+	// JSON
+	"JSONDeleteElement", "JSONFormatElements", "JSONGetElement", "JSONGetElementType", "JSONListKeys",
+	"JSONListValues", "JSONMakeArray", "JSONParse", "JSONParsedState", "JSONSetElement",
+	// 集計・繰り返し・財務・三角関数
+	"Average", "Count", "List", "Max", "Min", "StDev", "StDevP", "Sum", "Variance", "VarianceP",
+	"Extend", "GetRepetition", "Last", "GetSummary", "FV", "NPV", "PMT", "PV",
+	"Acos", "Asin", "Atan", "Cos", "Degrees", "Radians", "Sin", "Tan",
+	// 論理
+	"Case", "Choose", "Evaluate", "EvaluationError", "ExecuteFileMakerDataAPI", "ExecuteSQL", "GetAsBoolean",
+	"GetField", "GetFieldName", "GetLayoutObjectAttribute", "GetNthRecord", "If", "IsEmpty", "IsValid",
+	"IsValidExpression", "Let", "Lookup", "LookupNext", "Self", "SetRecursion", "While",
+	// 取得・デザイン・その他
+	"Get", "GetSensor", "Location", "LocationValues", "DatabaseNames", "FieldBounds", "FieldComment",
+	"FieldIDs", "FieldNames", "FieldRepetitions", "FieldStyle", "FieldType", "GetNextSerialValue",
+	"LayoutIDs", "LayoutNames", "LayoutObjectNames", "RelationInfo", "ScriptIDs", "ScriptNames",
+	"TableIDs", "TableNames", "BaseTableIDs", "BaseTableNames", "ValueListIDs", "ValueListItems",
+	"ValueListNames", "WindowNames", "GetFieldsOnLayout", "GetRecordIDsFromFoundSet", "GetTableDDL",
+	"GetEmbedding", "GetEmbeddingAsFile", "GetEmbeddingAsText", "CosineSimilarity", "GetTokenCount",
+	// 演算子
+	"and", "or", "not", "xor",
+)
+
+type nameSet map[string]bool
+
+func newNameSet(names ...string) nameSet {
+	set := nameSet{}
+	for _, name := range names {
+		set.add(name)
+	}
+	return set
+}
+
+func (set nameSet) add(name string)      { set[strings.ToLower(name)] = true }
+func (set nameSet) has(name string) bool { return set[strings.ToLower(name)] }
+
+func isCalcIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// calcIdentifier は計算式の中の名前（関数名・フィールド名・変数名）1 つ。
+type calcIdentifier struct {
+	start, end int // rune 単位の位置
+	name       string
+	call       bool // 直後が "(" の関数呼び出し
+	qualified  bool // "テーブル::" の後ろのフィールド名
+	occurrence bool // 直後が "::" のテーブルオカレンス名
+}
+
+// scanCalcIdentifiers は計算式の中の名前を返す。文字列リテラルとコメントは読み飛ばす。
+func scanCalcIdentifiers(runes []rune) []calcIdentifier {
+	var idents []calcIdentifier
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case r == '"':
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i += 2
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' && runes[i] != '\r' {
+				i++
+			}
+		case isCalcIdentRune(r):
+			start := i
+			for i < len(runes) && isCalcIdentRune(runes[i]) {
+				i++
+			}
+			j := i
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+			idents = append(idents, calcIdentifier{
+				start:      start,
+				end:        i,
+				name:       string(runes[start:i]),
+				call:       j < len(runes) && runes[j] == '(',
+				qualified:  start >= 2 && runes[start-1] == ':' && runes[start-2] == ':',
+				occurrence: i+1 < len(runes) && runes[i] == ':' && runes[i+1] == ':',
+			})
+		default:
+			i++
+		}
+	}
+	return idents
+}
+
+// calcFunctionCalls は計算式の中で関数として呼ばれている名前を返す。"テーブル::フィールド" の後ろの名前は関数とみなさない。
+func calcFunctionCalls(text string) []string {
+	var calls []string
+	for _, ident := range scanCalcIdentifiers([]rune(text)) {
+		if ident.call && !ident.qualified && !strings.HasPrefix(ident.name, "$") && !unicode.IsDigit([]rune(ident.name)[0]) {
+			calls = append(calls, ident.name)
+		}
+	}
+	return calls
+}
+
+// renameCalcFields は計算式の中のフィールド名（関数呼び出し・変数以外の名前）を rename の結果に置き換える。
+// "テーブル::フィールド名" は別のテーブルのフィールドを指すので、テーブル名もフィールド名も置き換えない。
+func renameCalcFields(text string, rename func(name string) (string, bool)) string {
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, ident := range scanCalcIdentifiers(runes) {
+		if ident.call || ident.qualified || ident.occurrence || strings.HasPrefix(ident.name, "$") {
+			continue
+		}
+		if newName, ok := rename(ident.name); ok {
+			b.WriteString(string(runes[last:ident.start]))
+			b.WriteString(newName)
+			last = ident.end
+		}
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// lintCalculations は計算式で使われている関数のうち、組み込み関数でもカスタム関数でもないものを警告として返す。
+func lintCalculations(s *schema) []string {
+	known := nameSet{}
+	for name := range builtinFunctions {
+		known.add(name)
+	}
+	for _, fn := range s.Functions {
+		known.add(fn.Name)
+	}
+
+	var warnings []string
+	check := func(origin, text string) {
+		for _, name := range calcFunctionCalls(text) {
+			if !known.has(name) {
+				warnings = append(warnings, fmt.Sprintf("%s: unknown function %s", origin, name))
+			}
+		}
+	}
+	for _, fn := range s.Functions {
+		check(fn.origin, fn.Calculation)
+	}
+	for _, t := range s.Tables {
+		for _, f := range t.Fields {
+			if f.Calculation != nil {
+				check(f.origin, f.Calculation.Text)
+			}
+			if f.AutoEnter.Calculation != nil {
+				check(f.origin, f.AutoEnter.Calculation.Text)
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCalcFunctionCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "nested", text: "Left ( Upper ( name ) ; 3 )", want: []string{"Left", "Upper"}},
+		{name: "string literal", text: `Trim ( "Foo ( bar )" )`, want: []string{"Trim"}},
+		{name: "escaped quote", text: `"a \" Foo ( b )" & Bar ( x )`, want: []string{"Bar"}},
+		{name: "comments", text: "/* Foo ( x ) */ Bar ( x ) // Baz ( y )\n& Qux ( z )", want: []string{"Bar", "Qux"}},
+		{name: "qualified field", text: "t::Foo ( 1 )", want: nil},
+		{name: "variable", text: "Let ( $x = 1 ; $x )", want: []string{"Let"}},
+		{name: "field only", text: "a + b", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calcFunctionCalls(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameCalcFields(t *testing.T) {
+	rename := func(name string) (string, bool) {
+		if strings.EqualFold(name, "amount") || name == "tax" {
+			return "p_" + name, true
+		}
+		return "", false
+	}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "fields", text: "amount + tax", want: "p_amount + p_tax"},
+		{name: "function with the same name", text: "amount ( tax )", want: "amount ( p_tax )"},
+		{name: "string literal and comment", text: `"amount" & tax /* amount */`, want: `"amount" & p_tax /* amount */`},
+		{name: "variable", text: "Let ( $amount = amount ; $amount )", want: "Let ( $amount = p_amount ; $amount )"},
+		{name: "unknown field", text: "price * 2", want: "price * 2"},
+		{name: "qualified field", text: "Other::amount & amount", want: "Other::amount & p_amount"},
+		{name: "table occurrence", text: "tax::rate * tax", want: "tax::rate * p_tax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renameCalcFields(tt.text, rename); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	configFileName = "config.xml"
+	// ユーザー設定ディレクトリ（os.UserConfigDir）の下のディレクトリ名
+	userConfigDirName = "generateTables"
+	// 組み込みのデフォルト設定を使うときのエラー表示用の名前
+	embeddedConfigName = "(built-in config.xml)"
+)
+
+// defaultConfig は config.xml が見つからないときに使う組み込みの設定（build/config.xml と同じ内容）。
+//
+//go:embed config.xml
+var defaultConfig []byte
+
+// configCandidates は config.xml を探す場所を優先順に返す。
+// ワークブックのディレクトリ（CSV のディレクトリを指定した場合はそのディレクトリ）、カレントディレクトリ、
+// ユーザー設定ディレクトリ、実行ファイルのディレクトリの順。
+func configCandidates(workbookPath string) []string {
+	var dirs []string
+	if workbookPath != "" {
+		if info, err := os.Stat(workbookPath); err == nil && info.IsDir() {
+			dirs = append(dirs, workbookPath)
+		} else {
+			dirs = append(dirs, filepath.Dir(workbookPath))
+		}
+	}
+	if dir, err := os.Getwd(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, userConfigDirName))
+	}
+	if dir, err := exeDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	paths := make([]string, len(dirs))
+	for i, dir := range dirs {
+		paths[i] = filepath.Join(dir, configFileName)
+	}
+	return paths
+}
+
+// findConfig は使う config.xml のパスを返す。-config の指定がなく、どこにも見つからなければ空（組み込みの設定を使う）。
+func findConfig(explicit, workbookPath string) (string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", withExitCode(exitConfig, err)
+		}
+		return explicit, nil
+	}
+	for _, path := range configCandidates(workbookPath) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", withExitCode(exitConfig, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return "", nil
+}
+
+func configName(configPath string) string {
+	if configPath == "" {
+		return embeddedConfigName
+	}
+	return configPath
+}
+
+// runMigrateConfig は migrate-config サブコマンド。config.xml を現在のバージョンの書式に書き換える。
+func runMigrateConfig(args []string) error {
+	fs := flag.NewFlagSet("migrate-config", flag.ExitOnError)
+	output := fs.String("o", "", "output file; defaults to overwriting the input (the original is kept as <input>.bak)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: generateTables migrate-config [-o new.xml] [/path/to/config.xml]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("migrate-config: expected at most 1 input, got %d", fs.NArg()))
+	}
+
+	path, err := findConfig(fs.Arg(0), "")
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return withExitCode(exitConfig, errors.New("migrate-config: no config.xml found"))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	migrated, err := migrateConfig(data)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("%s: %w", path, err))
+	}
+	// 移行で直せない誤り（未知の属性など）が残る場合は書き出さない
+	if _, err = checkConfig(migrated, path); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	if *output == "" {
+		if bytes.Equal(migrated, data) {
+			fmt.Printf("%s is already config version %d\n", path, currentConfigVersion)
+			return nil
+		}
+		if err = os.WriteFile(path+".bak", data, 0644); err != nil {
+			return withExitCode(exitOutput, err)
+		}
+		*output = path
+	}
+	if err = os.WriteFile(*output, migrated, 0644); err != nil {
+		return withExitCode(exitOutput, err)
+	}
+	fmt.Printf("wrote %s (config version %d)\n", *output, currentConfigVersion)
+	return nil
+}
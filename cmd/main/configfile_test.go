@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	book := filepath.Join(root, "book")
+	csvDir := filepath.Join(root, "csv")
+	cwd := filepath.Join(root, "cwd")
+	userDir := filepath.Join(root, "user")
+	for _, dir := range []string{book, csvDir, cwd, filepath.Join(userDir, userConfigDirName)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(cwd)
+	t.Setenv("HOME", userDir)
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+	write := func(path string) {
+		t.Helper()
+		if err := os.WriteFile(path, defaultConfig, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if path, err := findConfig("", filepath.Join(book, "Book.xlsx")); err != nil || path != "" {
+		t.Errorf("no config.xml: path = %q, err = %v, want the built-in config", path, err)
+	}
+
+	userConfig := filepath.Join(userDir, userConfigDirName, configFileName)
+	write(userConfig)
+	cwdConfig := filepath.Join(cwd, configFileName)
+	write(cwdConfig)
+	bookConfig := filepath.Join(book, configFileName)
+	write(bookConfig)
+	csvConfig := filepath.Join(csvDir, configFileName)
+	write(csvConfig)
+	tests := []struct {
+		name     string
+		explicit string
+		workbook string
+		want     string
+		code     int
+	}{
+		{name: "next to the workbook", workbook: filepath.Join(book, "Book.xlsx"), want: bookConfig},
+		{name: "in the CSV directory", workbook: csvDir, want: csvConfig},
+		{name: "current directory", workbook: filepath.Join(root, "other", "Book.xlsx"), want: cwdConfig},
+		{name: "explicit", explicit: userConfig, workbook: filepath.Join(book, "Book.xlsx"), want: userConfig},
+		{name: "explicit missing", explicit: filepath.Join(root, "missing.xml"), code: exitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := findConfig(tt.explicit, tt.workbook)
+			if tt.code != 0 {
+				if code := exitCode(err); code != tt.code {
+					t.Fatalf("err = %v (exit code %d), want exit code %d", err, code, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.want {
+				t.Errorf("path = %q, want %q", path, tt.want)
+			}
+		})
+	}
+
+	if err := os.Remove(cwdConfig); err != nil {
+		t.Fatal(err)
+	}
+	if path, _ := findConfig("", filepath.Join(root, "other", "Book.xlsx")); path != userConfig {
+		t.Errorf("path = %q, want the user config %q", path, userConfig)
+	}
+}
+
+// 組み込みの設定は build/config.xml と同じ内容にしておく。
+func TestEmbeddedConfigMatchesBuild(t *testing.T) {
+	build, err := os.ReadFile(filepath.Join("..", "..", "build", configFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(build, defaultConfig) {
+		t.Error("cmd/main/config.xml differs from build/config.xml")
+	}
+}
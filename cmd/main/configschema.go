@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// currentConfigVersion は config.xml の書式の現在のバージョン。<fmxmlsnippet version="..."> で指定する。
+const currentConfigVersion = 2
+
+// configAttr は要素のパス（"fmxmlsnippet/BaseTable/Field" など）と属性名。
+type configAttr struct {
+	element string
+	name    string
+}
+
+// configMigration は 1 つ前のバージョンから version への書式の変更。
+// 古いバージョンの config.xml では削除された属性を警告付きで読み飛ばし、migrate-config で書き換える。
+type configMigration struct {
+	version int
+	removed []configAttr
+}
+
+var configMigrations = []configMigration{
+	// 2: 自動入力の計算式は <Calculation> 要素で指定するので、AutoEnter の calculation 属性は使われていなかった
+	{version: 2, removed: []configAttr{{"fmxmlsnippet/BaseTable/Field/AutoEnter", "calculation"}}},
+}
+
+// configNode は config.xml の要素に書ける属性と子要素。fmxmlSnippet の xml タグから作る。
+type configNode struct {
+	attrs    map[string]bool // 属性名 → 値がセル参照か（config:"cell"）
+	children map[string]*configNode
+	cellText bool // 要素の文字列がセル参照
+}
+
+func newConfigNode(t reflect.Type) *configNode {
+	n := &configNode{attrs: map[string]bool{}, children: map[string]*configNode{}}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Name == "XMLName" {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("xml"), ",")
+		cell := sf.Tag.Get("config") == "cell"
+		switch opts {
+		case "attr":
+			n.attrs[name] = cell
+		case "cdata", "chardata":
+			n.cellText = cell
+		default:
+			if name == "" {
+				name = sf.Name
+			}
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				n.children[name] = newConfigNode(ft)
+			} else {
+				n.children[name] = &configNode{cellText: cell}
+			}
+		}
+	}
+	return n
+}
+
+var configSchema = newConfigNode(reflect.TypeOf(fmxmlSnippet{}))
+
+// configVersion は <fmxmlsnippet version="..."> の値を返す。省略時は 1。
+func configVersion(value string) (int, error) {
+	if value == "" {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid config version %q", value)
+	}
+	if version > currentConfigVersion {
+		return 0, fmt.Errorf("config version %d is newer than this generateTables supports (%d)", version, currentConfigVersion)
+	}
+	return version, nil
+}
+
+// removedIn は version の config.xml で、その後のバージョンで削除された属性なら削除されたバージョンを返す。
+func removedIn(version int, path, name string) (int, bool) {
+	for _, m := range configMigrations {
+		if m.version > version && slices.Contains(m.removed, configAttr{path, name}) {
+			return m.version, true
+		}
+	}
+	return 0, false
+}
+
+// configToken は config.xml の要素の開始タグと、その位置（行番号・バイト範囲）。
+type configToken struct {
+	xml.StartElement
+	path       string
+	line       int
+	start, end int64
+}
+
+// walkConfig は config.xml の開始タグと要素の文字列を順に visit に渡す。text は終了タグで要素の文字列全体を渡す。
+func walkConfig(data []byte, visit func(tok configToken, node *configNode), text func(tok configToken, node *configNode, value string)) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	type frame struct {
+		tok  configToken
+		node *configNode
+		text strings.Builder
+	}
+	var stack []*frame
+	for {
+		offset := dec.InputOffset()
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			tok := configToken{StartElement: t.Copy(), line: 1 + bytes.Count(data[:offset], []byte("\n")), start: offset, end: dec.InputOffset()}
+			var node *configNode
+			if len(stack) == 0 {
+				tok.path = t.Name.Local
+				if t.Name.Local == "fmxmlsnippet" {
+					node = configSchema
+				}
+			} else {
+				parent := stack[len(stack)-1]
+				tok.path = parent.tok.path + "/" + t.Name.Local
+				if parent.node != nil {
+					node = parent.node.children[t.Name.Local]
+				}
+			}
+			visit(tok, node)
+			stack = append(stack, &frame{tok: tok, node: node})
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			text(f.tok, f.node, f.text.String())
+		}
+	}
+}
+
+// checkConfig は config.xml を fmxmlSnippet に読み込む前に、未知の要素・属性とセル参照の誤りを行番号付きで検出する。
+// 古いバージョンで削除された属性は警告として返す。
+func checkConfig(data []byte, name string) ([]string, error) {
+	var problems, warnings []string
+	report := func(tok configToken, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s:%d: ", name, tok.line)+fmt.Sprintf(format, args...))
+	}
+	checkCell := func(tok configToken, what, value string) {
+		if value = strings.TrimSpace(value); value == "" {
+			return
+		}
+		if _, _, err := excelize.CellNameToCoordinates(value); err != nil {
+			report(tok, "%s of <%s> is not a cell reference: %q", what, tok.Name.Local, value)
+		}
+	}
+
+	version := currentConfigVersion
+	visit := func(tok configToken, node *configNode) {
+		if tok.path == "fmxmlsnippet" {
+			v, err := configVersion(attrValue(tok.StartElement, "version"))
+			if err != nil {
+				report(tok, "%v", err)
+			} else {
+				version = v
+			}
+		}
+		if node == nil {
+			if !strings.Contains(tok.path, "/") {
+				report(tok, "root element must be <fmxmlsnippet>, got <%s>", tok.Name.Local)
+			} else if parent := lookupConfigNode(tok.path[:strings.LastIndex(tok.path, "/")]); parent != nil {
+				report(tok, "unknown element <%s> in <%s>", tok.Name.Local, parentName(tok.path))
+			}
+			return
+		}
+		for _, a := range tok.Attr {
+			if a.Name.Space != "" || a.Name.Local == "xmlns" {
+				continue
+			}
+			cell, ok := node.attrs[a.Name.Local]
+			if !ok {
+				if v, removed := removedIn(version, tok.path, a.Name.Local); removed {
+					warnings = append(warnings, fmt.Sprintf("%s:%d: attribute %q of <%s> was removed in config version %d and is ignored (run generateTables migrate-config)", name, tok.line, a.Name.Local, tok.Name.Local, v))
+					continue
+				}
+				report(tok, "unknown attribute %q on <%s>%s", a.Name.Local, tok.Name.Local, suggestName(a.Name.Local, node.attrs))
+				continue
+			}
+			if cell {
+				checkCell(tok, fmt.Sprintf("attribute %q", a.Name.Local), a.Value)
+			}
+		}
+	}
+	text := func(tok configToken, node *configNode, value string) {
+		if node != nil && node.cellText {
+			checkCell(tok, "text", value)
+		}
+	}
+	if err := walkConfig(data, visit, text); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if version < currentConfigVersion {
+		warnings = append(warnings, fmt.Sprintf("%s: config version %d is older than %d (run generateTables migrate-config)", name, version, currentConfigVersion))
+	}
+	if len(problems) > 0 {
+		return warnings, errors.New(strings.Join(problems, "\n"))
+	}
+	return warnings, nil
+}
+
+func attrValue(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parentName(path string) string {
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-2]
+}
+
+// lookupConfigNode はパスの要素の configNode を返す。未知の要素の中の要素は重ねて報告しないように nil を返す。
+func lookupConfigNode(path string) *configNode {
+	parts := strings.Split(path, "/")
+	if parts[0] != "fmxmlsnippet" {
+		return nil
+	}
+	node := configSchema
+	for _, part := range parts[1:] {
+		if node = node.children[part]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// suggestName は大文字小文字だけが違う属性があれば、その候補を返す。
+// それ以外は書ける属性の一覧を返す（maxRepitition のような綴りの誤りに気付けるように）。
+func suggestName(name string, known map[string]bool) string {
+	names := make([]string, 0, len(known))
+	for k := range known {
+		if strings.EqualFold(k, name) {
+			return fmt.Sprintf(" (did you mean %q?)", k)
+		}
+		names = append(names, k)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	slices.Sort(names)
+	return " (known: " + strings.Join(names, ", ") + ")"
+}
+
+// migrateConfig は config.xml を現在のバージョンの書式に書き換える。削除された属性を取り除き、version を更新する。
+// 書式やコメントを残すため、開始タグの中だけを書き換える。
+func migrateConfig(data []byte) ([]byte, error) {
+	version := currentConfigVersion
+	var versionErr error
+	type edit struct {
+		start, end int64
+		tag        string
+	}
+	var edits []edit
+	visit := func(tok configToken, _ *configNode) {
+		tag := string(data[tok.start:tok.end])
+		if tok.path == "fmxmlsnippet" {
+			version, versionErr = configVersion(attrValue(tok.StartElement, "version"))
+			tag = setAttr(tag, "version", strconv.Itoa(currentConfigVersion))
+		}
+		for _, a := range tok.Attr {
+			if _, removed := removedIn(version, tok.path, a.Name.Local); removed {
+				tag = removeAttr(tag, a.Name.Local)
+			}
+		}
+		if tag != string(data[tok.start:tok.end]) {
+			edits = append(edits, edit{tok.start, tok.end, tag})
+		}
+	}
+	if err := walkConfig(data, visit, func(configToken, *configNode, string) {}); err != nil {
+		return nil, err
+	}
+	if versionErr != nil {
+		return nil, versionErr
+	}
+
+	var buf bytes.Buffer
+	last := int64(0)
+	for _, e := range edits {
+		buf.Write(data[last:e.start])
+		buf.WriteString(e.tag)
+		last = e.end
+	}
+	buf.Write(data[last:])
+	return buf.Bytes(), nil
+}
+
+func attrPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\s+` + regexp.QuoteMeta(name) + `\s*=\s*("[^"]*"|'[^']*')`)
+}
+
+func removeAttr(tag, name string) string {
+	return attrPattern(name).ReplaceAllString(tag, "")
+}
+
+func setAttr(tag, name, value string) string {
+	attr := fmt.Sprintf(` %s="%s"`, name, value)
+	if re := attrPattern(name); re.MatchString(tag) {
+		return re.ReplaceAllLiteralString(tag, attr)
+	}
+	end := len(tag) - len(">")
+	if strings.HasSuffix(tag, "/>") {
+		end = len(tag) - len("/>")
+	}
+	return strings.TrimRight(tag[:end], " \t\r\n") + attr + tag[end:]
+}
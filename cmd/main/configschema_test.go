@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		warnings []string
+		err      string
+	}{
+		{
+			name:   "built-in config",
+			config: string(defaultConfig),
+		},
+		{
+			name:   "unknown attribute with a different case",
+			config: "<fmxmlsnippet version=\"2\">\n<BaseTable name=\"K3\">\n<Field ID=\"A10\"/>\n</BaseTable>\n</fmxmlsnippet>",
+			err:    `config.xml:3: unknown attribute "ID" on <Field> (did you mean "id"?)`,
+		},
+		{
+			name:   "unknown element",
+			config: "<fmxmlsnippet version=\"2\">\n<Tables/>\n</fmxmlsnippet>",
+			err:    "config.xml:2: unknown element <Tables> in <fmxmlsnippet>",
+		},
+		{
+			name:   "not a cell reference",
+			config: "<fmxmlsnippet version=\"2\">\n<BaseTable name=\"table\"/>\n</fmxmlsnippet>",
+			err:    `config.xml:2: attribute "name" of <BaseTable> is not a cell reference: "table"`,
+		},
+		{
+			name:   "wrong root element",
+			config: "<config/>",
+			err:    "config.xml:1: root element must be <fmxmlsnippet>, got <config>",
+		},
+		{
+			name:   "newer version",
+			config: "<fmxmlsnippet version=\"99\"/>",
+			err:    "config.xml:1: config version 99 is newer than this generateTables supports (2)",
+		},
+		{
+			name:   "removed attribute",
+			config: "<fmxmlsnippet>\n<BaseTable name=\"K3\"><Field><AutoEnter calculation=\"AO10\"/></Field></BaseTable>\n</fmxmlsnippet>",
+			warnings: []string{
+				`config.xml:2: attribute "calculation" of <AutoEnter> was removed in config version 2 and is ignored (run generateTables migrate-config)`,
+				"config.xml: config version 1 is older than 2 (run generateTables migrate-config)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := checkConfig([]byte(tt.config), "config.xml")
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "version 1",
+			config: "<fmxmlsnippet type=\"FMObjectList\">\n\t<!-- comment -->\n\t<BaseTable name=\"K3\"><Field><AutoEnter constant=\"AL10\" calculation=\"AO10\"/></Field></BaseTable>\n</fmxmlsnippet>",
+			want:   "<fmxmlsnippet type=\"FMObjectList\" version=\"2\">\n\t<!-- comment -->\n\t<BaseTable name=\"K3\"><Field><AutoEnter constant=\"AL10\"/></Field></BaseTable>\n</fmxmlsnippet>",
+		},
+		{
+			name:   "current version",
+			config: "<fmxmlsnippet version=\"2\">\n\t<BaseTable name=\"K3\"/>\n</fmxmlsnippet>",
+			want:   "<fmxmlsnippet version=\"2\">\n\t<BaseTable name=\"K3\"/>\n</fmxmlsnippet>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrateConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if _, err := checkConfig(got, "config.xml"); err != nil {
+				t.Errorf("migrated config is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateConfigNewerVersion(t *testing.T) {
+	_, err := migrateConfig([]byte(`<fmxmlsnippet version="3"/>`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("err = %v, want a newer version error", err)
+	}
+}
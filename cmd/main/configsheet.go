@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// configSheetName はワークブックに列の割り当てを持たせるシート。あれば config.xml の BaseTable と Rows の代わりに使う。
+const configSheetName = "#CONFIG"
+
+// #CONFIG シートの列
+const (
+	configSheetPropertyColumn    = iota // A: プロパティ名
+	configSheetValueColumn              // B: 列（列名または見出し）や設定値
+	configSheetDefaultColumn            // C: セルが空のときの既定値
+	configSheetDefaultCellColumn        // D: テーブルのシートごとに既定値を上書きするセル
+)
+
+// fieldProperties は #CONFIG で指定できるフィールドのプロパティ名（小文字）→ fieldMapping の中の位置。
+// プロパティ名は config.xml の要素名と属性名を "." でつないだもの（autoEnter.constant、storage.global など）。
+var fieldProperties = map[string]fieldProperty{}
+
+type fieldProperty struct {
+	name  string
+	index []int
+}
+
+func init() {
+	addFieldProperties(reflect.TypeOf(fieldMapping{}), "", nil)
+}
+
+func addFieldProperties(t reflect.Type, prefix string, index []int) {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, opts, _ := strings.Cut(sf.Tag.Get("xml"), ",")
+		if name == "" {
+			name = sf.Name
+		}
+		idx := append(slices.Clone(index), i)
+		switch {
+		case sf.Type.Kind() == reflect.Struct && sf.Name != "XMLName":
+			addFieldProperties(sf.Type, join(lowerFirst(name)), idx)
+			continue
+		case sf.Tag.Get("config") != "cell":
+			continue
+		}
+		property := prefix
+		switch {
+		case opts == "attr" && (name != "value" || prefix == ""):
+			property = join(name)
+		case opts == "":
+			property = join(lowerFirst(name))
+		}
+		fieldProperties[strings.ToLower(property)] = fieldProperty{name: property, index: idx}
+	}
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func (m *fieldMapping) property(p fieldProperty) *string {
+	return reflect.ValueOf(m).Elem().FieldByIndex(p.index).Addr().Interface().(*string)
+}
+
+// fieldPropertyNames は #CONFIG で指定できるフィールドのプロパティ名を返す。
+func fieldPropertyNames() []string {
+	var names []string
+	for _, p := range fieldProperties {
+		names = append(names, p.name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+var columnNamePattern = regexp.MustCompile(`^[A-Z]{1,3}$`)
+
+// configSheetEntry は #CONFIG の 1 行。
+type configSheetEntry struct {
+	property, value, defaultValue, defaultCell string
+	origin                                     string
+}
+
+// applyConfigSheet はワークブックに #CONFIG シートがあれば、その列の割り当てで config.xml の BaseTable と Rows を置き換える。
+// シートの選択・命名規則など、それ以外の設定は config.xml のまま。
+//
+// #CONFIG は A 列にプロパティ名、B 列に列（"C" のような列名、または見出しの文字列）、C 列に既定値、
+// D 列にテーブルのシートごとに既定値を上書きするセルを書く。
+// table（テーブル名のセル）、start（データ開始行）、end、terminator、active、minColumns は B 列に値を書く。
+func applyConfigSheet(book sheetReader, rec fmxmlSnippet) (fmxmlSnippet, error) {
+	if !slices.Contains(book.GetSheetList(), configSheetName) {
+		return rec, nil
+	}
+	rows, err := book.GetRows(configSheetName)
+	if err != nil {
+		return rec, err
+	}
+
+	settings := map[string]configSheetEntry{}
+	var fields []configSheetEntry
+	for i, row := range rows {
+		cell := func(col int) string {
+			if col < len(row) {
+				return strings.TrimSpace(row[col])
+			}
+			return ""
+		}
+		e := configSheetEntry{
+			property:     cell(configSheetPropertyColumn),
+			value:        cell(configSheetValueColumn),
+			defaultValue: cell(configSheetDefaultColumn),
+			defaultCell:  cell(configSheetDefaultCellColumn),
+			origin:       fmt.Sprintf("%s!A%d", configSheetName, i+1),
+		}
+		// 空行と # で始まる行（見出しやメモ）は読み飛ばす
+		if e.property == "" || strings.HasPrefix(e.property, "#") {
+			continue
+		}
+		switch strings.ToLower(e.property) {
+		case "table", "start", "end", "terminator", "active", "mincolumns":
+			settings[strings.ToLower(e.property)] = e
+		default:
+			fields = append(fields, e)
+		}
+	}
+
+	start, err := strconv.Atoi(settings["start"].value)
+	if err != nil || start < 1 {
+		return rec, fmt.Errorf("%s: start (the first data row) must be a positive number", configSheetName)
+	}
+	headers := readHeaderColumns(book, rec, start)
+	column := func(e configSheetEntry) (string, error) {
+		if e.value == "" {
+			return "", nil
+		}
+		col, err := headers.column(e.value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %s: %w", e.origin, e.property, err)
+		}
+		return col + strconv.Itoa(start), nil
+	}
+
+	mapped := rec
+	mapped.BaseTable.Field = fieldMapping{}
+	// セル参照ではない属性（シリアル番号の増分と生成のタイミング）は config.xml のまま
+	mapped.BaseTable.Field.AutoEnter.Serial.Increment = rec.BaseTable.Field.AutoEnter.Serial.Increment
+	mapped.BaseTable.Field.AutoEnter.Serial.Generate = rec.BaseTable.Field.AutoEnter.Serial.Generate
+	mapped.BaseTable.Name = settings["table"].value
+	if _, _, err := excelize.CellNameToCoordinates(mapped.BaseTable.Name); err != nil {
+		return rec, fmt.Errorf("%s: table must be the cell of the table name: %w", configSheetName, err)
+	}
+	mapped.Rows = rowRules{
+		Start:      strconv.Itoa(start),
+		End:        settings["end"].value,
+		Terminator: settings["terminator"].value,
+		MinColumns: settings["mincolumns"].value,
+	}
+	if mapped.Rows.Active, err = column(settings["active"]); err != nil {
+		return rec, err
+	}
+
+	for _, e := range fields {
+		privilegeSet, isAccess := strings.CutPrefix(e.property, "access.")
+		p, ok := fieldProperties[strings.ToLower(e.property)]
+		if !ok && (!isAccess || privilegeSet == "") {
+			return rec, fmt.Errorf("%s: unknown property %q (%s, access.<privilege set>)", e.origin, e.property, strings.Join(fieldPropertyNames(), ", "))
+		}
+		cellName, err := column(e)
+		if err != nil {
+			return rec, err
+		}
+		if !ok {
+			mapped.BaseTable.Field.Access = append(mapped.BaseTable.Field.Access, accessMapping{PrivilegeSet: privilegeSet, Value: cellName})
+			continue
+		}
+		*mapped.BaseTable.Field.property(p) = cellName
+		if e.defaultCell != "" {
+			if _, _, err := excelize.CellNameToCoordinates(e.defaultCell); err != nil {
+				return rec, fmt.Errorf("%s: %s: %w", e.origin, e.property, err)
+			}
+		}
+		if e.defaultValue != "" || e.defaultCell != "" {
+			// config.xml の <Defaults> より優先する
+			mapped = mapped.withDefault(defaultRule{Property: p.name, Value: e.defaultValue, Cell: e.defaultCell})
+		}
+	}
+	if _, err := mapped.dataRange(); err != nil {
+		return rec, fmt.Errorf("%s: %w", configSheetName, err)
+	}
+	return mapped, nil
+}
+
+// headerColumns はデータ開始行より上の行にある見出しの文字列 → 列名。
+// 見出しは、config.xml の規則で選ばれる最初のシートから読む（テーブルのシートはすべて同じ列の並びとする）。
+type headerColumns map[string][]string
+
+func readHeaderColumns(book sheetReader, rec fmxmlSnippet, start int) headerColumns {
+	headers := headerColumns{}
+	rules := rec.sheetFilter()
+	for _, sheetName := range book.GetSheetList() {
+		if !rules.selects(sheetName) {
+			continue
+		}
+		rows, err := book.GetRows(sheetName)
+		if err != nil {
+			continue
+		}
+		for _, row := range rows[:min(start-1, len(rows))] {
+			for i, value := range row {
+				if value = strings.TrimSpace(value); value == "" {
+					continue
+				}
+				col, _ := excelize.ColumnNumberToName(i + 1)
+				if !slices.Contains(headers[value], col) {
+					headers[value] = append(headers[value], col)
+				}
+			}
+		}
+		break
+	}
+	return headers
+}
+
+// column は "C" のような列名はそのまま、それ以外は見出しとして列名を返す。列名と同じ見出しは "[ID]" のように [] で囲む。
+func (headers headerColumns) column(value string) (string, error) {
+	if columnNamePattern.MatchString(value) {
+		return value, nil
+	}
+	header := value
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		header = value[1 : len(value)-1]
+	}
+	cols := headers[header]
+	switch len(cols) {
+	case 0:
+		return "", fmt.Errorf("header %q not found above the data rows", header)
+	case 1:
+		return cols[0], nil
+	}
+	return "", fmt.Errorf("header %q is ambiguous (columns %s); use the column name", header, strings.Join(cols, ", "))
+}
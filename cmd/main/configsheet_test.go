@@ -0,0 +1,145 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyConfigSheetSerial(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		config    [][]string
+		cells     map[string]string
+		nextValue string
+	}{
+		{
+			name: "column name",
+			config: [][]string{
+				{"# プロパティ", "列", "既定値"},
+				{"table", "K3"},
+				{"start", "10"},
+				{"name", "C"},
+				{"autoEnter.constant", "AL"},
+				{"autoEnter.serial.nextValue", "AR"},
+			},
+			cells:     map[string]string{"C": "id", "AL": "シリアル番号+変更禁止", "AR": "100"},
+			nextValue: "100",
+		},
+		{
+			name: "default value",
+			config: [][]string{
+				{"table", "K3"},
+				{"start", "10"},
+				{"name", "B"},
+				{"autoEnter.constant", "D"},
+				{"autoEnter.serial.nextValue", "E", "1"},
+			},
+			cells:     map[string]string{"B": "id", "D": "シリアル番号"},
+			nextValue: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := testBook(t, "t", tt.cells)
+			book.add(configSheetName, tt.config)
+			mapped, err := applyConfigSheet(book, rec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := parseField(book, mapped.withDefaults(book, "t"), "t", 9)
+			want := serial{Increment: "1", NextValue: tt.nextValue, Generate: "OnCreation"}
+			if f.AutoEnter.Serial == nil || *f.AutoEnter.Serial != want {
+				t.Errorf("serial = %+v, want %+v", f.AutoEnter.Serial, want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigSheet(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := [][]string{
+		{"customer"},
+		{"ID", "名前", "型", "メモ", "メモ", "Staff"},
+		{"", "id", "数字型", "", "", "view"},
+		{"", "name", "", "氏名", "", ""},
+	}
+	tests := []struct {
+		name   string
+		config [][]string
+		want   []string // "名前 dataType コメント アクセス権"
+		err    string
+	}{
+		{
+			name: "headers and column names",
+			config: [][]string{
+				{"# プロパティ", "列", "既定値", "既定値のセル"},
+				{"table", "A1"},
+				{"start", "3"},
+				{"id", "[ID]"},
+				{"name", "名前"},
+				{"dataType", "型", "日付型"},
+				{"comment", "D"},
+				{"access.Staff", "Staff"},
+			},
+			want: []string{"id Number  Staff: view", "name Date 氏名 "},
+		},
+		{
+			name:   "ambiguous header",
+			config: [][]string{{"table", "A1"}, {"start", "3"}, {"comment", "メモ"}},
+			err:    `#CONFIG!A3: comment: header "メモ" is ambiguous (columns D, E); use the column name`,
+		},
+		{
+			name:   "unknown header",
+			config: [][]string{{"table", "A1"}, {"start", "3"}, {"name", "氏名"}},
+			err:    `#CONFIG!A3: name: header "氏名" not found above the data rows`,
+		},
+		{
+			name:   "unknown property",
+			config: [][]string{{"table", "A1"}, {"start", "3"}, {"colour", "B"}},
+			err:    `#CONFIG!A3: unknown property "colour"`,
+		},
+		{
+			name:   "no start",
+			config: [][]string{{"table", "A1"}, {"name", "B"}},
+			err:    "#CONFIG: start (the first data row) must be a positive number",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newGridReader()
+			book.add("t", slices.Clone(sheet))
+			book.add(configSheetName, tt.config)
+			mapped, err := applyConfigSheet(book, rec)
+			if (err == nil) != (tt.err == "") || err != nil && !strings.HasPrefix(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			s, err := parseWorkbook(book, mapped, sheetFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s.Tables) != 1 || s.Tables[0].Name != "customer" {
+				t.Fatalf("tables = %v, want customer", s.Tables)
+			}
+			var got []string
+			for _, f := range s.Tables[0].Fields {
+				got = append(got, strings.Join([]string{f.Name, f.DataType, f.Comment, describeAccess(f.Access)}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// defaultRules は config.xml の <Defaults>。列のセルが空のときに使うプロパティの値。
+// 指定のないプロパティは field.defaults の値になる。
+type defaultRules struct {
+	Rules []defaultRule `xml:"Default"`
+}
+
+// defaultRule はプロパティ 1 つの既定値。cell を指定すると、テーブルのシートのそのセル（データ行より上のセルなど）に
+// 値があれば、そのテーブルだけ既定値を上書きする。
+type defaultRule struct {
+	Property string `xml:"property,attr"`
+	Value    string `xml:"value,attr"`
+	Cell     string `xml:"cell,attr" config:"cell"`
+}
+
+func (rec fmxmlSnippet) defaultRules() []defaultRule {
+	if rec.Defaults == nil {
+		return nil
+	}
+	return rec.Defaults.Rules
+}
+
+func (rules *defaultRules) validate() error {
+	if rules == nil {
+		return nil
+	}
+	for _, r := range rules.Rules {
+		if _, ok := fieldProperties[strings.ToLower(r.Property)]; !ok {
+			return fmt.Errorf("Defaults: unknown property %q (%s)", r.Property, strings.Join(fieldPropertyNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// withDefault はプロパティの既定値を追加した rec を返す。同じプロパティの既定値は後のものが優先される。
+func (rec fmxmlSnippet) withDefault(rule defaultRule) fmxmlSnippet {
+	rules := &defaultRules{Rules: slices.Clone(rec.defaultRules())}
+	rules.Rules = append(rules.Rules, rule)
+	rec.Defaults = rules
+	return rec
+}
+
+// withDefaults は sheetName のシートで使う既定値を設定した rec を返す。sheetName が空のときはシートのセルを読まない。
+func (rec fmxmlSnippet) withDefaults(book sheetReader, sheetName string) fmxmlSnippet {
+	rec.defaults = fieldMapping{}
+	for _, r := range rec.defaultRules() {
+		value := rec.defaults.property(fieldProperties[strings.ToLower(r.Property)])
+		if r.Value != "" {
+			*value = r.Value
+		}
+		if r.Cell != "" && sheetName != "" {
+			if v, _ := book.GetCellValue(sheetName, r.Cell); strings.TrimSpace(v) != "" {
+				*value = strings.TrimSpace(v)
+			}
+		}
+	}
+	return rec
+}
+
+// fieldDefault は field のプロパティと、config.xml の <Defaults> から作った fieldMapping の同じプロパティの組。
+type fieldDefault struct {
+	value *string
+	def   *string
+}
+
+// configDefaults は YAML/JSON の入力にも使える <Defaults> のプロパティの一覧。
+// ID・名前・計算式・集計対象・自動入力の種類と値・値一覧はフィールドごとに書くものなので含めない。
+func (f *field) configDefaults(def *fieldMapping) []fieldDefault {
+	return []fieldDefault{
+		{&f.FieldType, &def.FieldType},
+		{&f.DataType, &def.DataType},
+		{&f.Comment, &def.Comment},
+		{&f.AutoEnter.AlwaysEvaluate, &def.AutoEnter.AlwaysEvaluate},
+		{&f.AutoEnter.OverwriteExistingValue, &def.AutoEnter.OverwriteExistingValue},
+		{&f.AutoEnter.AllowEditing, &def.AutoEnter.AllowEditing},
+		{&f.AutoEnter.Furigana, &def.AutoEnter.Furigana},
+		{&f.AutoEnter.Lookup, &def.AutoEnter.Lookup},
+		{&f.Validation.Type, &def.Validation.Type},
+		{&f.Validation.Message, &def.Validation.Message},
+		{&f.Validation.Valuelist, &def.Validation.Valuelist},
+		{&f.Validation.Calculation, &def.Validation.Calculation},
+		{&f.Validation.AlwaysValidateCalculation, &def.Validation.AlwaysValidateCalculation},
+		{&f.Validation.StrictDataType, &def.Validation.StrictDataType.Value},
+		{&f.Validation.Unique, &def.Validation.Unique.Value},
+		{&f.Validation.NotEmpty, &def.Validation.NotEmpty.Value},
+		{&f.Validation.MaxDataLength, &def.Validation.MaxDataLength.Value},
+		{&f.Validation.Existing, &def.Validation.Existing.Value},
+		{&f.Validation.StrictValidation, &def.Validation.StrictValidation.Value},
+		{&f.Storage.AutoIndex, &def.Storage.AutoIndex},
+		{&f.Storage.Index, &def.Storage.Index},
+		{&f.Storage.IndexLanguage, &def.Storage.IndexLanguage},
+		{&f.Storage.Global, &def.Storage.Global},
+		{&f.Storage.MaxRepetition, &def.Storage.MaxRepetition},
+	}
+}
+
+// schemaFileDefaults は YAML/JSON の入力に使う <Defaults> の値を返す（cell は読まない）。
+// configDefaults にないプロパティの既定値はワークブックの列にしか使えないのでエラーにする。
+func (rec fmxmlSnippet) schemaFileDefaults() (fieldMapping, error) {
+	def := rec.withDefaults(nil, "").defaults
+	supported := (&field{}).configDefaults(&def)
+	for _, r := range rec.defaultRules() {
+		value := def.property(fieldProperties[strings.ToLower(r.Property)])
+		if !slices.ContainsFunc(supported, func(d fieldDefault) bool { return d.def == value }) {
+			return def, fmt.Errorf("Defaults: property %q cannot be used with YAML/JSON input", r.Property)
+		}
+	}
+	return def, nil
+}
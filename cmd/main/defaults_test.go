@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// field.defaults の組み込みの値は、組み込みの config.xml の <Defaults> と同じでなければならない。
+func TestDefaultsMatchEmbeddedConfig(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := rec.schemaFileDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &field{}
+	configured := f.configDefaults(&def)
+	fallback := f.defaults()
+	for i, d := range fallback {
+		j := slices.IndexFunc(configured, func(c fieldDefault) bool { return c.value == d.value })
+		if j < 0 {
+			t.Errorf("field.defaults()[%d] (%q) cannot be set in <Defaults>", i, d.def)
+			continue
+		}
+		if got := *configured[j].def; got != d.def {
+			t.Errorf("field.defaults()[%d] = %q, config.xml <Defaults> = %q", i, d.def, got)
+		}
+	}
+	for _, c := range configured {
+		if *c.def != "" && !slices.ContainsFunc(fallback, func(d defaultValue) bool { return d.value == c.value }) {
+			t.Errorf("config.xml <Defaults> value %q has no fallback in field.defaults()", *c.def)
+		}
+	}
+}
+
+func TestSchemaFileDefaults(t *testing.T) {
+	rec := fmxmlSnippet{Defaults: &defaultRules{Rules: []defaultRule{
+		{Property: "storage.indexLanguage", Value: "English"},
+		{Property: "Validation.NotEmpty", Value: "True", Cell: "K5"},
+	}}}
+	def, err := rec.schemaFileDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Storage.IndexLanguage != "English" || def.Validation.NotEmpty.Value != "True" {
+		t.Errorf("defaults = %q, %q", def.Storage.IndexLanguage, def.Validation.NotEmpty.Value)
+	}
+
+	rec = rec.withDefault(defaultRule{Property: "autoEnter.constant", Value: "固定値"})
+	want := `Defaults: property "autoEnter.constant" cannot be used with YAML/JSON input`
+	if _, err := rec.schemaFileDefaults(); err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestSetConfigDefaults(t *testing.T) {
+	var def fieldMapping
+	def.Storage.IndexLanguage = "English"
+	def.Validation.NotEmpty.Value = "True"
+
+	f := &field{Name: "memo"}
+	f.setConfigDefaults(def)
+	if f.Storage.IndexLanguage != "English" || f.Validation.NotEmpty != "True" || f.DataType != "Text" {
+		t.Errorf("indexLanguage = %q, notEmpty = %q, dataType = %q", f.Storage.IndexLanguage, f.Validation.NotEmpty, f.DataType)
+	}
+	explicit := &field{Name: "code", Storage: storage{IndexLanguage: "Japanese"}}
+	explicit.setConfigDefaults(def)
+	if explicit.Storage.IndexLanguage != "Japanese" {
+		t.Errorf("explicit indexLanguage = %q, want Japanese", explicit.Storage.IndexLanguage)
+	}
+
+	// convert はこの既定値と同じ値を省略し、組み込みの値と同じでも既定値と違う値は残す
+	f.clearDefaults(def)
+	explicit.clearDefaults(def)
+	if f.Storage.IndexLanguage != "" || f.Validation.NotEmpty != "" || f.DataType != "" {
+		t.Errorf("cleared indexLanguage = %q, notEmpty = %q, dataType = %q", f.Storage.IndexLanguage, f.Validation.NotEmpty, f.DataType)
+	}
+	if explicit.Storage.IndexLanguage != "Japanese" {
+		t.Errorf("cleared explicit indexLanguage = %q, want Japanese", explicit.Storage.IndexLanguage)
+	}
+}
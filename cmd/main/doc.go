@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+)
+
+func isTrue(value string) bool {
+	return strings.EqualFold(value, "True")
+}
+
+// データ辞書の列見出し
+var docColumns = []string{"ID", "フィールド名", "タイプ", "コメント", "計算式", "自動入力", "入力値の制限", "保存"}
+
+// アクセス権の列は、いずれかのフィールドにアクセス権が指定されているときだけ載せる
+const docAccessColumn = "アクセス権"
+
+func docColumnsFor(s *schema) []string {
+	if len(privilegeSets(s)) == 0 {
+		return docColumns
+	}
+	return append(slices.Clip(docColumns), docAccessColumn)
+}
+
+// docRow はデータ辞書の 1 行分（フィールド 1 つ）の表示用の値。
+type docRow struct {
+	ID, Name, Type, Comment, Calculation, AutoEnter, Validation, Storage, Access string
+}
+
+func (r docRow) cells(columns int) []string {
+	cells := []string{r.ID, r.Name, r.Type, r.Comment, r.Calculation, r.AutoEnter, r.Validation, r.Storage, r.Access}
+	return cells[:columns]
+}
+
+func newDocRow(f *field) docRow {
+	r := docRow{
+		ID:         f.ID,
+		Name:       f.Name,
+		Type:       f.DataType,
+		Comment:    f.Comment,
+		AutoEnter:  describeAutoEnter(f.AutoEnter),
+		Validation: describeValidation(f.Validation),
+		Storage:    describeStorage(f.Storage),
+		Access:     describeAccess(f.Access),
+	}
+	switch f.FieldType {
+	case "Calculated":
+		r.Type = "計算 (" + f.DataType + ")"
+		if f.Calculation != nil {
+			r.Calculation = f.Calculation.Text
+		}
+	case "Summary":
+		r.Type = "集計 (" + f.DataType + ")"
+		r.Calculation = fmt.Sprintf("%s(%s)", f.Summary.Operation, f.Summary.target.Name)
+		if f.Summary.Repetition == "Individually" {
+			r.Calculation += " 繰り返しごと"
+		}
+	}
+	return r
+}
+
+func describeAutoEnter(ae autoEnter) string {
+	var parts []string
+	switch ae.Kind {
+	case "":
+	case "固定値":
+		parts = append(parts, "固定値: "+ae.ConstantData)
+	case "計算値":
+		if ae.Calculation != nil {
+			parts = append(parts, "計算値: "+ae.Calculation.Text)
+		}
+		if isTrue(ae.OverwriteExistingValue) {
+			parts = append(parts, "既存値を置き換え")
+		}
+	case "シリアル番号":
+		if ae.Serial != nil {
+			parts = append(parts, fmt.Sprintf("シリアル番号 (次の値 %s, 増分 %s)", ae.Serial.NextValue, ae.Serial.Increment))
+		}
+	default:
+		parts = append(parts, ae.Kind)
+	}
+	if isTrue(ae.Lookup) {
+		parts = append(parts, "ルックアップ")
+	}
+	if isTrue(ae.Furigana) {
+		parts = append(parts, "ふりがな")
+	}
+	if !isTrue(ae.AllowEditing) {
+		parts = append(parts, "変更禁止")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeValidation(v validation) string {
+	var parts []string
+	if v.StrictDataType != "" {
+		parts = append(parts, "型: "+v.StrictDataType)
+	}
+	if isTrue(v.Unique) {
+		parts = append(parts, "ユニーク")
+	}
+	if isTrue(v.NotEmpty) {
+		parts = append(parts, "空欄不可")
+	}
+	if v.MaxDataLength != "" {
+		parts = append(parts, "最大 "+v.MaxDataLength+" 文字")
+	}
+	if isTrue(v.Existing) {
+		parts = append(parts, "既存値")
+	}
+	if len(v.Values) > 0 {
+		parts = append(parts, "値一覧: "+strings.Join(v.Values, " / "))
+	} else if isTrue(v.Valuelist) {
+		parts = append(parts, "値一覧")
+	}
+	if isTrue(v.Calculation) {
+		parts = append(parts, "計算式")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeStorage(st storage) string {
+	var parts []string
+	if isTrue(st.Global) {
+		parts = append(parts, "グローバル")
+	}
+	if st.MaxRepetition != "" && st.MaxRepetition != "1" {
+		parts = append(parts, "繰り返し "+st.MaxRepetition)
+	}
+	if st.Index != "" && st.Index != "None" {
+		parts = append(parts, "索引: "+st.Index)
+	}
+	return strings.Join(parts, ", ")
+}
+
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// renderMarkdown は BaseTable ごとの表からなる Markdown のデータ辞書を返す。
+func renderMarkdown(s *schema, _ outputOptions) ([]byte, error) {
+	columns := docColumnsFor(s)
+	var buf bytes.Buffer
+	buf.WriteString("# データ辞書\n")
+	for _, t := range s.Tables {
+		fmt.Fprintf(&buf, "\n## %s\n\n", t.Name)
+		buf.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+		buf.WriteString(strings.Repeat("|---", len(columns)) + "|\n")
+		for _, f := range t.Fields {
+			cells := newDocRow(f).cells(len(columns))
+			for i, c := range cells {
+				cells[i] = markdownCellReplacer.Replace(c)
+			}
+			buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("doc").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="UTF-8">
+<title>データ辞書</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; vertical-align: top; text-align: left; white-space: pre-wrap; }
+th { background: #f0f0f0; }
+</style>
+</head>
+<body>
+<h1>データ辞書</h1>
+<ul>
+{{- range .Tables}}
+<li><a href="#{{.Name}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- range .Tables}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+<table>
+<tr>{{range $.Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderHTML は BaseTable ごとの表からなる HTML のデータ辞書を返す。
+func renderHTML(s *schema, _ outputOptions) ([]byte, error) {
+	type docTable struct {
+		Name string
+		Rows [][]string
+	}
+	data := struct {
+		Columns []string
+		Tables  []docTable
+	}{Columns: docColumnsFor(s)}
+	for _, t := range s.Tables {
+		dt := docTable{Name: t.Name}
+		for _, f := range t.Fields {
+			dt.Rows = append(dt.Rows, newDocRow(f).cells(len(data.Columns)))
+		}
+		data.Tables = append(data.Tables, dt)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func docSchema() *schema {
+	id := &field{ID: "1", Name: "id", FieldType: "Normal", DataType: "Number",
+		AutoEnter:  autoEnter{Kind: "シリアル番号", AllowEditing: "False", Serial: &serial{NextValue: "1", Increment: "1"}},
+		Validation: validation{Unique: "True", NotEmpty: "True"},
+		Storage:    storage{Index: "Minimal", MaxRepetition: "1"}}
+	memo := &field{ID: "2", Name: "memo", FieldType: "Normal", DataType: "Text", Comment: "a|b\n<c>",
+		AutoEnter:  autoEnter{AllowEditing: "True"},
+		Validation: validation{Values: []string{"A", "B"}},
+		Storage:    storage{Global: "True", MaxRepetition: "3"}}
+	total := &field{ID: "3", Name: "total", FieldType: "Calculated", DataType: "Number", Calculation: &calculation{Text: "id * 2"},
+		AutoEnter: autoEnter{AllowEditing: "True"}}
+	count := &field{ID: "4", Name: "count", FieldType: "Summary", DataType: "Number",
+		Summary: &summaryInfo{Operation: "Count", Repetition: "Together", target: id}, AutoEnter: autoEnter{AllowEditing: "True"}}
+	return &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{id, memo, total, count}}}}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	out, err := renderMarkdown(docSchema(), outputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# データ辞書
+
+## t
+
+| ID | フィールド名 | タイプ | コメント | 計算式 | 自動入力 | 入力値の制限 | 保存 |
+|---|---|---|---|---|---|---|---|
+| 1 | id | Number |  |  | シリアル番号 (次の値 1, 増分 1), 変更禁止 | ユニーク, 空欄不可 | 索引: Minimal |
+| 2 | memo | Text | a\|b<br><c> |  |  | 値一覧: A / B | グローバル, 繰り返し 3 |
+| 3 | total | 計算 (Number) |  | id * 2 |  |  |  |
+| 4 | count | 集計 (Number) |  | Count(id) |  |  |  |
+`
+	if string(out) != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	out, err := renderHTML(docSchema(), outputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<li><a href="#t">t</a></li>`,
+		`<h2 id="t">t</h2>`,
+		"<td>2</td><td>memo</td><td>Text</td><td>a|b\n&lt;c&gt;</td>",
+		"<td>Count(id)</td>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("%q not found in\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+// 終了コード。スクリプトから失敗の種類を判別できるように分ける。
+const (
+	exitFailure = 1 // その他のエラー
+	exitUsage   = 2 // 引数の誤り（flag パッケージと同じ）
+	exitConfig  = 3 // config.xml を読めない・書式の誤り
+	exitInput   = 4 // 入力ファイルを読めない・テーブル定義の誤り
+	exitLint    = 5 // 命名規則の違反（severity="error"）や -strict での警告
+	exitOutput  = 6 // 出力の生成・書き込み・クリップボードへのコピーの失敗
+)
+
+// codedError は終了コードを持つエラー。
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withExitCode は err に終了コードを付ける。すでに終了コードが付いていればそのまま返す。
+func withExitCode(code int, err error) error {
+	var coded *codedError
+	if err == nil || errors.As(err, &coded) {
+		return err
+	}
+	return &codedError{code: code, err: err}
+}
+
+func exitCode(err error) int {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return exitFailure
+}
+
+// warn は警告を標準エラー出力に書く（-debug のときは debug.log にも残す）。
+func warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Println("warning:", msg)
+	fmt.Fprintln(os.Stderr, "warning:", msg)
+}
+
+// fail はエラーを標準エラー出力に書き（-debug のときは debug.log にも残す）、エラーの種類に応じた終了コードで終了する。
+func fail(err error) {
+	log.Println(err)
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "plain error", err: base, want: exitFailure},
+		{name: "coded", err: withExitCode(exitConfig, base), want: exitConfig},
+		{name: "first code wins", err: withExitCode(exitOutput, withExitCode(exitInput, base)), want: exitInput},
+		{name: "wrapped", err: fmt.Errorf("load: %w", withExitCode(exitLint, base)), want: exitLint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode = %d, want %d", got, tt.want)
+			}
+			if !errors.Is(tt.err, base) {
+				t.Errorf("%v does not wrap the original error", tt.err)
+			}
+		})
+	}
+	if withExitCode(exitConfig, nil) != nil {
+		t.Error("withExitCode(nil) != nil")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import "fmt"
+
+// fieldRules はフィールドタイプと保存方法ごとに、Field 要素に出力する設定。
+// FileMaker がテーブルをコピーしたときの XML に合わせ、適用されない設定は出力しない。
+type fieldRules struct {
+	autoEnter  bool // 自動入力（通常タイプのみ）
+	validation bool // 入力値の制限（通常タイプのみ）
+	storage    bool // 保存オプション（集計タイプにはない）
+	index      bool // 索引（グローバルフィールドには索引がない）
+	uniqueness bool // ユニーク・既存値の制限（グローバルフィールドでは検証できない）
+}
+
+func rulesFor(f *field) fieldRules {
+	global := isTrue(f.Storage.Global)
+	switch f.FieldType {
+	case "Calculated":
+		return fieldRules{storage: true, index: !global}
+	case "Summary":
+		return fieldRules{}
+	}
+	return fieldRules{autoEnter: true, validation: true, storage: true, index: !global, uniqueness: !global}
+}
+
+func hasValidation(v validation) bool {
+	return v.StrictDataType != "" || isTrue(v.Unique) || isTrue(v.NotEmpty) || isTrue(v.Existing) ||
+		v.MaxDataLength != "" || isTrue(v.Valuelist) || isTrue(v.Calculation) || len(v.Values) > 0
+}
+
+// lintFieldRules はシートに入力されていても出力されない（フィールドタイプに適用されない）設定を警告として返す。
+func lintFieldRules(s *schema) []string {
+	var warnings []string
+	for _, t := range s.Tables {
+		for _, f := range t.Fields {
+			warn := func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf("%s: field %q: ", f.origin, f.Name)+fmt.Sprintf(format, args...))
+			}
+			rules := rulesFor(f)
+			if !isKnownAutoEnterKind(f.AutoEnter.Kind) {
+				warn("unknown auto-enter kind %q", f.AutoEnter.Kind)
+			}
+			if !rules.autoEnter && f.AutoEnter.Kind != "" {
+				warn("auto-enter does not apply to %s fields", f.FieldType)
+			}
+			if !rules.validation && hasValidation(f.Validation) {
+				warn("validation does not apply to %s fields", f.FieldType)
+			}
+			if !rules.storage && (isTrue(f.Storage.Global) || f.Storage.MaxRepetition != "1") {
+				warn("storage options do not apply to %s fields", f.FieldType)
+			}
+			if rules.storage && !rules.index && f.Storage.Index != "None" {
+				warn("indexing does not apply to global fields")
+			}
+			if rules.validation && !rules.uniqueness && (isTrue(f.Validation.Unique) || isTrue(f.Validation.Existing)) {
+				warn("unique and existing value validation do not apply to global fields")
+			}
+		}
+	}
+	return warnings
+}
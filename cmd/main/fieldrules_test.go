@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderFieldElements(t *testing.T) {
+	target := &field{ID: "1", Name: "amount"}
+	tests := []struct {
+		name     string
+		field    field
+		elements []string
+		index    string // Storage の index
+		unique   string // Validation の Unique の value
+	}{
+		{
+			name:     "normal",
+			field:    field{FieldType: "Normal", DataType: "Text", Validation: validation{Unique: "True"}, Storage: storage{Index: "All", Global: "False"}},
+			elements: []string{"Comment", "AutoEnter", "Validation", "Storage"},
+			index:    "All",
+			unique:   "True",
+		},
+		{
+			name:     "global",
+			field:    field{FieldType: "Normal", DataType: "Text", Validation: validation{Unique: "True"}, Storage: storage{Index: "All", Global: "True"}},
+			elements: []string{"Comment", "AutoEnter", "Validation", "Storage"},
+			index:    "None",
+			unique:   "False",
+		},
+		{
+			name:     "calculated",
+			field:    field{FieldType: "Calculated", DataType: "Number", Calculation: &calculation{Text: "1"}, Storage: storage{Index: "Minimal"}},
+			elements: []string{"Comment", "Calculation", "Storage"},
+			index:    "Minimal",
+		},
+		{
+			name:     "summary",
+			field:    field{FieldType: "Summary", DataType: "Number", Summary: &summaryInfo{Operation: "Total", Repetition: "Together", target: target}},
+			elements: []string{"SummaryInfo", "Comment"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.field
+			f.Name = "f"
+			fieldElement := renderField(&f)
+			var elements []string
+			for child := fieldElement.FirstChild; child != nil; child = child.NextSibling {
+				elements = append(elements, child.Data)
+			}
+			if !reflect.DeepEqual(elements, tt.elements) {
+				t.Errorf("elements = %q, want %q", elements, tt.elements)
+			}
+			if st := fieldElement.SelectElement("Storage"); st != nil && st.SelectAttr("index") != tt.index {
+				t.Errorf("index = %q, want %q", st.SelectAttr("index"), tt.index)
+			}
+			if u := fieldElement.SelectElement("Validation/Unique"); u != nil && u.SelectAttr("value") != tt.unique {
+				t.Errorf("unique = %q, want %q", u.SelectAttr("value"), tt.unique)
+			}
+		})
+	}
+}
+
+func TestLintFieldRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		field field
+		want  []string
+	}{
+		{
+			name:  "normal",
+			field: field{FieldType: "Normal", AutoEnter: autoEnter{Kind: "固定値"}, Validation: validation{NotEmpty: "True"}, Storage: storage{Index: "All", MaxRepetition: "1"}},
+		},
+		{
+			name:  "calculated with auto-enter and validation",
+			field: field{FieldType: "Calculated", AutoEnter: autoEnter{Kind: "固定値"}, Validation: validation{NotEmpty: "True"}, Storage: storage{Index: "None", MaxRepetition: "1"}},
+			want: []string{
+				`t!A10: field "f": auto-enter does not apply to Calculated fields`,
+				`t!A10: field "f": validation does not apply to Calculated fields`,
+			},
+		},
+		{
+			name:  "summary with storage options",
+			field: field{FieldType: "Summary", Storage: storage{Global: "True", MaxRepetition: "1"}},
+			want:  []string{`t!A10: field "f": storage options do not apply to Summary fields`},
+		},
+		{
+			name:  "global with index and unique",
+			field: field{FieldType: "Normal", Validation: validation{Unique: "True"}, Storage: storage{Global: "True", Index: "All", MaxRepetition: "1"}},
+			want: []string{
+				`t!A10: field "f": indexing does not apply to global fields`,
+				`t!A10: field "f": unique and existing value validation do not apply to global fields`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.field
+			f.Name, f.origin = "f", "t!A10"
+			s := &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{&f}}}}
+			if got := lintFieldRules(s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("warnings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/xuri/excelize/v2"
+)
+
+// customFunction はカスタム関数の定義。
+type customFunction struct {
+	Name        string   `json:"name" yaml:"name"`
+	Parameters  []string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Calculation string   `json:"calculation" yaml:"calculation"`
+	Comment     string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+
+	origin string
+}
+
+// functionColumns は config.xml の <CustomFunctions>。カスタム関数のシート名と、最初の定義行の各列のセル参照。
+type functionColumns struct {
+	Sheet       string `xml:"sheet,attr"`
+	Name        string `xml:"name,attr" config:"cell"`
+	Parameters  string `xml:"parameters,attr" config:"cell"`
+	Calculation string `xml:"calculation,attr" config:"cell"`
+	Comment     string `xml:"comment,attr" config:"cell"`
+}
+
+var defaultFunctionColumns = functionColumns{Sheet: "#FUNCTIONS", Name: "A2", Parameters: "B2", Calculation: "C2", Comment: "D2"}
+
+// functionColumns は config.xml の <CustomFunctions> を返す。省略した属性はデフォルトの列を使う。
+func (rec fmxmlSnippet) functionColumns() functionColumns {
+	if rec.CustomFunctions == nil {
+		return defaultFunctionColumns
+	}
+	c := *rec.CustomFunctions
+	for _, d := range []defaultValue{
+		{&c.Sheet, defaultFunctionColumns.Sheet},
+		{&c.Name, defaultFunctionColumns.Name},
+		{&c.Parameters, defaultFunctionColumns.Parameters},
+		{&c.Calculation, defaultFunctionColumns.Calculation},
+		{&c.Comment, defaultFunctionColumns.Comment},
+	} {
+		if *d.value == "" {
+			*d.value = d.def
+		}
+	}
+	return c
+}
+
+// splitParameters は引数の一覧（; , または改行区切り）を分ける。
+func splitParameters(value string) []string {
+	var params []string
+	for _, p := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' || r == '\n' || r == '\r' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// parseFunctions はカスタム関数のシート（デフォルトは #FUNCTIONS）を読み込む。シートがなければ何もしない。
+func parseFunctions(book sheetReader, rec fmxmlSnippet) ([]*customFunction, error) {
+	cols := rec.functionColumns()
+	if !slices.Contains(book.GetSheetList(), cols.Sheet) {
+		return nil, nil
+	}
+	_, start, err := excelize.SplitCellName(cols.Name)
+	if err != nil {
+		return nil, fmt.Errorf("CustomFunctions name: %w", err)
+	}
+	rows, err := book.GetRows(cols.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var functions []*customFunction
+	for rowIndex := start - 1; rowIndex < len(rows); rowIndex++ {
+		cell := func(cellName string) string {
+			value, _ := book.GetCellValue(cols.Sheet, rowCell(cellName, rowIndex))
+			return value
+		}
+		name := strings.TrimSpace(cell(cols.Name))
+		if name == "" {
+			continue
+		}
+		functions = append(functions, &customFunction{
+			Name:        name,
+			Parameters:  splitParameters(cell(cols.Parameters)),
+			Calculation: cell(cols.Calculation),
+			Comment:     cell(cols.Comment),
+			origin:      cellOrigin(cols.Sheet, cols.Name, rowIndex),
+		})
+	}
+	return functions, nil
+}
+
+// validateFunctions は関数名と引数名の重複や空欄を検出する。FileMaker と同じく大文字小文字は区別しない。
+func validateFunctions(functions []*customFunction) error {
+	seen := map[string]*customFunction{}
+	for _, fn := range functions {
+		if fn.Name == "" {
+			return fmt.Errorf("%s: custom function name is empty", fn.origin)
+		}
+		key := strings.ToLower(fn.Name)
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("custom function %q is defined in %s and %s", fn.Name, prev.origin, fn.origin)
+		}
+		seen[key] = fn
+		params := map[string]bool{}
+		for _, p := range fn.Parameters {
+			if params[strings.ToLower(p)] {
+				return fmt.Errorf("%s: custom function %q has duplicate parameter %q", fn.origin, fn.Name, p)
+			}
+			params[strings.ToLower(p)] = true
+		}
+	}
+	return nil
+}
+
+// renderFunctionSnippet はカスタム関数の fmxmlsnippet を返す。コメントは計算式の先頭に /* */ で入れる。
+func renderFunctionSnippet(s *schema, _ outputOptions) ([]byte, error) {
+	if len(s.Functions) == 0 {
+		return nil, errors.New("no custom functions defined")
+	}
+	rootElement := newElement("fmxmlsnippet", "type", "FMObjectList")
+	for i, fn := range s.Functions {
+		functionElement := &xmlquery.Node{
+			Data: "CustomFunction",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "id"}, Value: strconv.Itoa(i + 1)},
+				{Name: xml.Name{Local: "functionArity"}, Value: strconv.Itoa(len(fn.Parameters))},
+				{Name: xml.Name{Local: "visible"}, Value: "True"},
+				{Name: xml.Name{Local: "parameters"}, Value: strings.Join(fn.Parameters, ";")},
+				{Name: xml.Name{Local: "name"}, Value: fn.Name},
+			},
+		}
+		text := fn.Calculation
+		if fn.Comment != "" {
+			text = "/* " + strings.ReplaceAll(fn.Comment, "*/", "* /") + " */\n" + text
+		}
+		xmlquery.AddChild(functionElement, newTextElement("Calculation", text))
+		xmlquery.AddChild(rootElement, functionElement)
+	}
+	return []byte(rootElement.OutputXML(true)), nil
+}
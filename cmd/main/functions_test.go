@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestParseFunctions(t *testing.T) {
+	book := newGridReader()
+	book.add("#FUNCTIONS", [][]string{
+		{"name", "parameters", "calculation", "comment"},
+		{"Tax", "price; rate", "Round ( price * rate ; 0 )", "税額"},
+		{"", "ignored"},
+		{"Today", "", "Get ( CurrentDate )"},
+	})
+	functions, err := parseFunctions(book, fmxmlSnippet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*customFunction{
+		{Name: "Tax", Parameters: []string{"price", "rate"}, Calculation: "Round ( price * rate ; 0 )", Comment: "税額", origin: "#FUNCTIONS!A2"},
+		{Name: "Today", Calculation: "Get ( CurrentDate )", origin: "#FUNCTIONS!A4"},
+	}
+	if !reflect.DeepEqual(functions, want) {
+		t.Errorf("functions = %+v, want %+v", functions, want)
+	}
+
+	if functions, err := parseFunctions(newGridReader(), fmxmlSnippet{}); err != nil || functions != nil {
+		t.Errorf("without the sheet: functions = %v, err = %v", functions, err)
+	}
+}
+
+func TestValidateFunctions(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions []*customFunction
+		err       string
+	}{
+		{name: "valid", functions: []*customFunction{{Name: "Tax", Parameters: []string{"price", "rate"}}, {Name: "Today"}}},
+		{name: "duplicate name", functions: []*customFunction{{Name: "Tax", origin: "A2"}, {Name: "tax", origin: "A3"}}, err: `custom function "tax" is defined in A2 and A3`},
+		{name: "duplicate parameter", functions: []*customFunction{{Name: "Tax", Parameters: []string{"price", "Price"}, origin: "A2"}}, err: `A2: custom function "Tax" has duplicate parameter "Price"`},
+		{name: "empty name", functions: []*customFunction{{origin: "book.yaml: functions[0]"}}, err: "book.yaml: functions[0]: custom function name is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFunctions(tt.functions)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRenderFunctionSnippet(t *testing.T) {
+	s := &schema{Functions: []*customFunction{
+		{Name: "Tax", Parameters: []string{"price", "rate"}, Calculation: "price * rate", Comment: "税額 */"},
+	}}
+	out, err := renderFunctionSnippet(s, outputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := xmlquery.Parse(strings.NewReader(string(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := xmlquery.FindOne(doc, "//CustomFunction")
+	if fn == nil {
+		t.Fatalf("no CustomFunction in\n%s", out)
+	}
+	for attr, want := range map[string]string{"id": "1", "name": "Tax", "functionArity": "2", "parameters": "price;rate"} {
+		if got := fn.SelectAttr(attr); got != want {
+			t.Errorf("%s = %q, want %q", attr, got, want)
+		}
+	}
+	if got, want := fn.SelectElement("Calculation").InnerText(), "/* 税額 * / */\nprice * rate"; got != want {
+		t.Errorf("calculation = %q, want %q", got, want)
+	}
+
+	if _, err := renderFunctionSnippet(&schema{}, outputOptions{}); err == nil {
+		t.Error("renderFunctionSnippet without functions succeeded")
+	}
+}
+
+func TestLintCalculations(t *testing.T) {
+	s := &schema{
+		Functions: []*customFunction{{Name: "Tax", Calculation: "Round ( price ; 0 ) + Unknown ( 1 )", origin: "#FUNCTIONS!A2"}},
+		Tables: []*baseTable{{Name: "t", Fields: []*field{
+			{Name: "total", Calculation: &calculation{Text: "tax ( price ; 0.1 ) + Sum ( a )"}, origin: "t!A10"},
+			{Name: "code", AutoEnter: autoEnter{Calculation: &calculation{Text: "Foo ( id )"}}, origin: "t!A11"},
+		}}},
+	}
+	want := []string{"#FUNCTIONS!A2: unknown function Unknown", "t!A11: unknown function Foo"}
+	if got := lintCalculations(s); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Data API の fieldData を読むための型。数字は空欄が "" で返り、日付・時刻は MM/DD/YYYY 形式の文字列で返る。
+const goHelpers = `
+// Number は数字フィールドの値。空欄は "" で返るため json.Number では読めない。
+type Number string
+
+func (n *Number) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*n = Number(s)
+		return nil
+	}
+	*n = Number(b)
+	return nil
+}
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	if n == "" {
+		return []byte(` + "`" + `""` + "`" + `), nil
+	}
+	return []byte(n), nil
+}
+
+// Float64 は値を数値として返す。空欄は 0。
+func (n Number) Float64() (float64, error) {
+	if n == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Date は日付フィールドの値（MM/DD/YYYY）。
+type Date string
+
+// Time は値を time.Time として返す。空欄はゼロ値。
+func (d Date) Time() (time.Time, error) { return parseFileMakerTime("01/02/2006", string(d)) }
+
+// Time は時刻フィールドの値（HH:MM:SS）。
+type Time string
+
+// Time は値を 0000-01-01 の時刻として返す。空欄はゼロ値。
+func (t Time) Time() (time.Time, error) { return parseFileMakerTime("15:04:05", string(t)) }
+
+// TimeStamp はタイムスタンプフィールドの値（MM/DD/YYYY HH:MM:SS）。
+type TimeStamp string
+
+// Time は値を time.Time として返す。空欄はゼロ値。
+func (ts TimeStamp) Time() (time.Time, error) {
+	return parseFileMakerTime("01/02/2006 15:04:05", string(ts))
+}
+
+func parseFileMakerTime(layout, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(layout, value, time.Local)
+}
+`
+
+var goTypes = map[string]string{
+	"Text": "string", "Number": "Number", "Date": "Date", "Time": "Time", "TimeStamp": "TimeStamp", "Binary": "string",
+}
+
+// goIdentifier はフィールド名・テーブル名から公開される Go の識別子を作る。
+// 英数字以外で区切って単語の先頭を大文字にし、大文字で始まらない場合（日本語や数字）は prefix を付ける。
+func goIdentifier(name, prefix string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		default:
+			upper = true
+		}
+	}
+	ident := b.String()
+	if ident == "" || !token.IsExported(ident) {
+		ident = prefix + ident
+	}
+	return ident
+}
+
+// uniqueIdentifier は used にない識別子を返す（重複したときは _2, _3 … を付ける）。
+func uniqueIdentifier(ident string, used map[string]bool) string {
+	unique := ident
+	for i := 2; used[unique]; i++ {
+		unique = ident + "_" + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+func writeGoComment(buf *bytes.Buffer, indent, text string) {
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimRight(line, " \t"))
+	}
+}
+
+// renderGo は BaseTable ごとに Data API の fieldData を読み書きする struct を定義した Go のソースを返す。
+func renderGo(s *schema, opts outputOptions) ([]byte, error) {
+	if !token.IsIdentifier(opts.goPackage) {
+		return nil, fmt.Errorf("invalid Go package name %q", opts.goPackage)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by generateTables; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.goPackage)
+	buf.WriteString("import (\n\t\"encoding/json\"\n\t\"strconv\"\n\t\"time\"\n)\n")
+
+	usedTypes := map[string]bool{"Number": true, "Date": true, "Time": true, "TimeStamp": true}
+	for _, t := range s.Tables {
+		typeName := uniqueIdentifier(goIdentifier(t.Name, "Table"), usedTypes)
+		fmt.Fprintf(&buf, "\n// %s は BaseTable %q のフィールド。\n", typeName, t.Name)
+		fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+		usedFields := map[string]bool{}
+		for _, f := range t.Fields {
+			goType := goTypes[f.DataType]
+			if goType == "" {
+				goType = "string"
+			}
+			if f.Comment != "" {
+				writeGoComment(&buf, "\t", f.Comment)
+			}
+			tag := "`json:" + strconv.Quote(f.Name+",omitempty") + "`"
+			if strings.Contains(f.Name, "`") {
+				tag = strconv.Quote("json:" + strconv.Quote(f.Name+",omitempty"))
+			}
+			fmt.Fprintf(&buf, "\t%s %s %s\n", uniqueIdentifier(goIdentifier(f.Name, "F"), usedFields), goType, tag)
+		}
+		buf.WriteString("}\n")
+	}
+	buf.WriteString(goHelpers)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go source: %w", err)
+	}
+	return src, nil
+}
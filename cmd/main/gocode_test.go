@@ -0,0 +1,71 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		name, prefix, want string
+	}{
+		{"customer_id", "F", "CustomerId"},
+		{"created at", "F", "CreatedAt"},
+		{"Name", "F", "Name"},
+		{"顧客名", "F", "F顧客名"},
+		{"1st", "F", "F1st"},
+		{"//----------", "F", "F"},
+		{"@Sys", "Table", "Sys"},
+	}
+	for _, tt := range tests {
+		if got := goIdentifier(tt.name, tt.prefix); got != tt.want {
+			t.Errorf("goIdentifier(%q, %q) = %q, want %q", tt.name, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestRenderGo(t *testing.T) {
+	s := &schema{Tables: []*baseTable{{Name: "customer", Fields: []*field{
+		{Name: "id", DataType: "Number"},
+		{Name: "ID", DataType: "Text", Comment: "旧 ID\n（移行用）"},
+		{Name: "birthday", DataType: "Date"},
+		{Name: "a`b", DataType: "Text"},
+		{Name: "customer id", DataType: "Text"},
+		{Name: "customer_id", DataType: "Text"},
+	}}}}
+	out, err := renderGo(s, outputOptions{goPackage: "fm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "fm.go", out, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, out)
+	}
+	// gofmt で揃えた空白は 1 つにして比べる
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	for _, want := range []string{
+		"package fm",
+		"type Customer struct {",
+		"Id Number `json:\"id,omitempty\"`",
+		"// 旧 ID",
+		"// （移行用）",
+		"ID string `json:\"ID,omitempty\"`",
+		"Birthday Date `json:\"birthday,omitempty\"`",
+		"AB string \"json:\\\"a`b,omitempty\\\"\"",
+		"CustomerId string `json:\"customer id,omitempty\"`",
+		"CustomerId_2 string `json:\"customer_id,omitempty\"`",
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("%q not found in\n%s", want, out)
+		}
+	}
+
+	if _, err := renderGo(s, outputOptions{goPackage: "my-package"}); err == nil {
+		t.Error("invalid package name is accepted")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// includeDirective はフィールド名の列に書くと、フィールドグループのシートの行をその位置に展開する（"@include 名前 [接頭辞]"）。
+const includeDirective = "@include"
+
+// parseInclude はフィールド名のセルが @include 行なら、展開するグループの名前と接頭辞を返す。
+func parseInclude(value string) (group, prefix string, ok bool) {
+	args := strings.Fields(value)
+	if len(args) == 0 || args[0] != includeDirective {
+		return "", "", false
+	}
+	if len(args) > 1 {
+		group = args[1]
+	}
+	if len(args) > 2 {
+		prefix = args[2]
+	}
+	return group, prefix, true
+}
+
+// parseSheetFields はシートのデータ行をフィールドとして読み込み、@include 行をフィールドグループのシートの行に展開する。
+// including は展開中のシート名（循環の検出用）。
+func parseSheetFields(book sheetReader, rec fmxmlSnippet, dr dataRange, sheetName string, rows [][]string, including []string) ([]*field, error) {
+	var fields []*field
+	included := map[*field]bool{}
+	for _, rowIndex := range dataRowIndexes(rows, dr) {
+		name := returnCellValue(book, sheetName, rowIndex, rec.BaseTable.Field.Name, "")
+		group, prefix, ok := parseInclude(name)
+		if !ok {
+			fields = append(fields, parseField(book, rec, sheetName, rowIndex))
+			continue
+		}
+		origin := cellOrigin(sheetName, rec.BaseTable.Field.Name, rowIndex)
+		groupFields, err := includeFields(book, rec, dr, origin, group, prefix, append(including, sheetName))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range groupFields {
+			included[f] = true
+		}
+		fields = append(fields, groupFields...)
+	}
+
+	// 展開したフィールドの名前がシートのフィールドや別の展開と重なる場合は、接頭辞で区別するようにエラーにする
+	seen := map[string]*field{}
+	for _, f := range fields {
+		key := strings.ToLower(f.Name)
+		if prev, ok := seen[key]; ok && (included[f] || included[prev]) {
+			return nil, fmt.Errorf("%s: field %q collides with %s (use an %s prefix)", f.origin, f.Name, prev.origin, includeDirective)
+		}
+		seen[key] = f
+	}
+	return fields, nil
+}
+
+// includeFields は #グループ名 のシートのフィールドを、名前に接頭辞を付けて返す。
+// 同じグループを 1 つのテーブルに何度でも展開できるように、グループのシートの ID は使わず assignFieldIDs で採番する。
+func includeFields(book sheetReader, rec fmxmlSnippet, dr dataRange, origin, group, prefix string, including []string) ([]*field, error) {
+	if group == "" {
+		return nil, fmt.Errorf("%s: %s needs a field group name", origin, includeDirective)
+	}
+	sheetName := "#" + group
+	if slices.Contains(including, sheetName) {
+		return nil, fmt.Errorf("%s: field group %q includes itself (%s)", origin, group, strings.Join(append(including, sheetName), " -> "))
+	}
+	if !slices.Contains(book.GetSheetList(), sheetName) {
+		return nil, fmt.Errorf("%s: field group sheet %q not found", origin, sheetName)
+	}
+	rows, err := book.GetRows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseSheetFields(book, rec, dr, sheetName, rows, including)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, f := range fields {
+		names[strings.ToLower(f.Name)] = true
+	}
+	// グループ内の計算式や集計対象が参照するフィールド名にも接頭辞を付ける
+	rename := func(name string) (string, bool) {
+		if prefix == "" || !names[strings.ToLower(name)] {
+			return "", false
+		}
+		return prefix + name, true
+	}
+	for _, f := range fields {
+		f.Name = prefix + f.Name
+		f.ID, f.defaultID = "", ""
+		f.origin += " (" + origin + ")"
+		if f.nameOrigin != "" {
+			f.nameOrigin += " (" + origin + ")"
+		}
+		if f.Calculation != nil {
+			f.Calculation.Text = renameCalcFields(f.Calculation.Text, rename)
+		}
+		if f.AutoEnter.Calculation != nil {
+			f.AutoEnter.Calculation.Text = renameCalcFields(f.AutoEnter.Calculation.Text, rename)
+		}
+		if f.Summary != nil {
+			f.Summary.Field = renameSummaryRef(f.Summary.Field, rename)
+		}
+	}
+	return fields, nil
+}
+
+// renameSummaryRef は集計対象の参照（"名前"・"テーブル::名前"・従来の "id.名前"）の名前を rename の結果に置き換える。
+// グループのシートの ID は使わないので、"id.名前" は名前だけにする。
+func renameSummaryRef(ref string, rename func(name string) (string, bool)) string {
+	ref = strings.TrimSpace(ref)
+	if table, name, ok := strings.Cut(ref, "::"); ok {
+		if newName, ok := rename(name); ok {
+			return table + "::" + newName
+		}
+		return ref
+	}
+	if newName, ok := rename(ref); ok {
+		return newName
+	}
+	if _, name, ok := strings.Cut(ref, "."); ok {
+		if newName, ok := rename(name); ok {
+			return newName
+		}
+	}
+	return ref
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSheetFieldsInclude(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dr, err := rec.dataRange()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	field := func(name string) map[string]string { return map[string]string{"C": name} }
+	tests := []struct {
+		name   string
+		sheets map[string][]map[string]string // シート名 → 10 行目からの行
+		want   []string
+		err    string
+	}{
+		{
+			name: "expand",
+			sheets: map[string][]map[string]string{
+				"t":        {field("id"), field("@include Address"), field("memo")},
+				"#Address": {field("City"), field("Zip")},
+			},
+			want: []string{"id", "City", "Zip", "memo"},
+		},
+		{
+			name: "prefix",
+			sheets: map[string][]map[string]string{
+				"t":        {field("@include Address B_"), field("@include Address S_")},
+				"#Address": {field("City")},
+			},
+			want: []string{"B_City", "S_City"},
+		},
+		{
+			name: "nested",
+			sheets: map[string][]map[string]string{
+				"t":        {field("@include Contact")},
+				"#Contact": {field("Tel"), field("@include Address")},
+				"#Address": {field("City")},
+			},
+			want: []string{"Tel", "City"},
+		},
+		{
+			name: "includes itself",
+			sheets: map[string][]map[string]string{
+				"t":  {field("@include A")},
+				"#A": {field("@include B")},
+				"#B": {field("@include A")},
+			},
+			err: `#B!C10: field group "A" includes itself (t -> #A -> #B -> #A)`,
+		},
+		{
+			name: "missing group sheet",
+			sheets: map[string][]map[string]string{
+				"t": {field("id"), field("@include Address")},
+			},
+			err: `t!C11: field group sheet "#Address" not found`,
+		},
+		{
+			name: "no group name",
+			sheets: map[string][]map[string]string{
+				"t": {field("@include")},
+			},
+			err: "t!C10: @include needs a field group name",
+		},
+		{
+			name: "collides with a sheet field",
+			sheets: map[string][]map[string]string{
+				"t":        {field("city"), field("@include Address")},
+				"#Address": {field("City")},
+			},
+			err: `#Address!A10 (t!C11): field "City" collides with t!A10 (use an @include prefix)`,
+		},
+		{
+			name: "collides with another expansion",
+			sheets: map[string][]map[string]string{
+				"t":        {field("@include Address"), field("@include Address")},
+				"#Address": {field("City")},
+			},
+			err: `#Address!A10 (t!C11): field "City" collides with #Address!A10 (t!C10) (use an @include prefix)`,
+		},
+		{
+			name: "same names without expansion",
+			sheets: map[string][]map[string]string{
+				"t": {field("a"), field("a")},
+			},
+			want: []string{"a", "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newGridReader()
+			for sheetName, fields := range tt.sheets {
+				book.add(sheetName, testRows(t, fields...))
+			}
+			rows, _ := book.GetRows("t")
+			fields, err := parseSheetFields(book, rec.withDefaults(book, "t"), dr, "t", rows, nil)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("fields = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeFieldsRenameReferences(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dr, err := rec.dataRange()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		row  map[string]string // グループの 2 行目（1 行目は City）
+		calc string
+		ref  string
+	}{
+		{
+			name: "calculation",
+			row:  map[string]string{"C": "Label", "H": "Calculated", "Q": "Other::City & City & Address::Zip"},
+			calc: "Other::City & B_City & Address::Zip",
+		},
+		{
+			name: "summary by name",
+			row:  map[string]string{"C": "Count", "H": "Summary", "K": "Together.Count", "Q": "City"},
+			ref:  "B_City",
+		},
+		{
+			name: "summary by table and name",
+			row:  map[string]string{"C": "Count", "H": "Summary", "K": "Together.Count", "Q": "t::City"},
+			ref:  "t::B_City",
+		},
+		{
+			name: "summary by legacy id.name",
+			row:  map[string]string{"C": "Count", "H": "Summary", "K": "Together.Count", "Q": "1.City"},
+			ref:  "B_City",
+		},
+		{
+			name: "summary of an outside field",
+			row:  map[string]string{"C": "Count", "H": "Summary", "K": "Together.Count", "Q": "id"},
+			ref:  "id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newGridReader()
+			book.add("t", testRows(t, map[string]string{"C": "@include Address B_"}))
+			book.add("#Address", testRows(t, map[string]string{"C": "City"}, tt.row))
+			fields, err := includeFields(book, rec.withDefaults(book, "t"), dr, "t!C10", "Address", "B_", []string{"t"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := fields[1]
+			if f.Calculation != nil && f.Calculation.Text != tt.calc {
+				t.Errorf("calculation = %q, want %q", f.Calculation.Text, tt.calc)
+			}
+			if f.Summary != nil && f.Summary.Field != tt.ref {
+				t.Errorf("summary field = %q, want %q", f.Summary.Field, tt.ref)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// jsonMember は jsonObject のメンバー 1 つ。
+type jsonMember struct {
+	name  string
+	value any
+}
+
+// jsonObject はメンバーの順序を保ったまま書き出す JSON オブジェクト（map ではフィールドの定義順が失われる）。
+type jsonObject []jsonMember
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+type jsonSchemaProperty struct {
+	Type        any    `json:"type"` // 型名、または型名のスライス
+	Description string `json:"description,omitempty"`
+	MaxLength   int    `json:"maxLength,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+}
+
+type jsonSchemaTable struct {
+	Type       string     `json:"type"`
+	Properties jsonObject `json:"properties"`
+	Required   []string   `json:"required,omitempty"`
+}
+
+// Data API の fieldData での型。日付・時刻は "MM/DD/YYYY" などの文字列で返るので format は付けない。
+var jsonSchemaTypes = map[string]string{
+	"Text": "string", "Number": "number", "Date": "string", "Time": "string", "TimeStamp": "string", "Binary": "string",
+}
+
+func newJSONSchemaProperty(f *field) jsonSchemaProperty {
+	typ := jsonSchemaTypes[f.DataType]
+	if typ == "" {
+		typ = "string"
+	}
+	p := jsonSchemaProperty{Type: typ, Description: f.Comment}
+	if n, err := strconv.Atoi(f.Validation.MaxDataLength); err == nil && typ == "string" {
+		p.MaxLength = n
+	}
+	for _, v := range f.Validation.Values {
+		if typ == "number" {
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				p.Enum = append(p.Enum, n)
+				continue
+			}
+		}
+		p.Enum = append(p.Enum, v)
+	}
+	// Data API は空の数字フィールドを "" で返す
+	if typ == "number" && !isTrue(f.Validation.NotEmpty) {
+		p.Type = []string{"number", "string"}
+		if p.Enum != nil {
+			p.Enum = append(p.Enum, "")
+		}
+	}
+	// 計算・集計、変更禁止の自動入力は書き込めない。オブジェクトフィールドも fieldData では URL しか返らない
+	p.ReadOnly = f.FieldType == "Calculated" || f.FieldType == "Summary" ||
+		(f.AutoEnter.Kind != "" && !isTrue(f.AutoEnter.AllowEditing)) || f.DataType == "Binary"
+	return p
+}
+
+func newJSONSchemaTable(t *baseTable) jsonSchemaTable {
+	st := jsonSchemaTable{Type: "object", Properties: jsonObject{}}
+	for _, f := range t.Fields {
+		st.Properties = append(st.Properties, jsonMember{f.Name, newJSONSchemaProperty(f)})
+		if isTrue(f.Validation.NotEmpty) {
+			st.Required = append(st.Required, f.Name)
+		}
+	}
+	return st
+}
+
+func jsonSchemaTables(s *schema) jsonObject {
+	tables := jsonObject{}
+	for _, t := range s.Tables {
+		tables = append(tables, jsonMember{t.Name, newJSONSchemaTable(t)})
+	}
+	return tables
+}
+
+func marshalJSONDocument(doc jsonObject) ([]byte, error) {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// renderJSONSchema は BaseTable ごとのスキーマを $defs に並べた JSON Schema を返す。
+func renderJSONSchema(s *schema, _ outputOptions) ([]byte, error) {
+	return marshalJSONDocument(jsonObject{
+		{"$schema", "https://json-schema.org/draft/2020-12/schema"},
+		{"$defs", jsonSchemaTables(s)},
+	})
+}
+
+// renderOpenAPI は同じスキーマを OpenAPI 3.1 の components.schemas として返す。
+func renderOpenAPI(s *schema, _ outputOptions) ([]byte, error) {
+	return marshalJSONDocument(jsonObject{
+		{"openapi", "3.1.0"},
+		{"info", jsonObject{{"title", "FileMaker tables"}, {"version", "1.0.0"}}},
+		{"components", jsonObject{{"schemas", jsonSchemaTables(s)}}},
+	})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewJSONSchemaProperty(t *testing.T) {
+	tests := []struct {
+		name  string
+		field field
+		want  string
+	}{
+		{
+			name:  "text",
+			field: field{DataType: "Text", Validation: validation{MaxDataLength: "10"}},
+			want:  `{"type":"string","maxLength":10}`,
+		},
+		{
+			name:  "number",
+			field: field{DataType: "Number"},
+			want:  `{"type":["number","string"]}`,
+		},
+		{
+			name:  "not empty number",
+			field: field{DataType: "Number", Validation: validation{NotEmpty: "True"}},
+			want:  `{"type":"number"}`,
+		},
+		{
+			name:  "number with values",
+			field: field{DataType: "Number", Validation: validation{Values: []string{"1", "2"}}},
+			want:  `{"type":["number","string"],"enum":[1,2,""]}`,
+		},
+		{
+			name:  "not empty number with values",
+			field: field{DataType: "Number", Validation: validation{NotEmpty: "True", Values: []string{"1", "2"}}},
+			want:  `{"type":"number","enum":[1,2]}`,
+		},
+		{
+			name:  "calculated number",
+			field: field{FieldType: "Calculated", DataType: "Number"},
+			want:  `{"type":["number","string"],"readOnly":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(newJSONSchemaProperty(&tt.field))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// レイアウトオブジェクトの配置（ポイント）
+const (
+	layoutTop             = 20
+	layoutLabelLeft       = 20
+	layoutLabelWidth      = 140
+	layoutFieldLeft       = 170
+	layoutFieldWidth      = 260
+	layoutRowHeight       = 20
+	layoutContainerHeight = 80
+	layoutRowGap          = 6
+)
+
+// layoutSkipKinds は -layout-skip に指定できる値。
+var layoutSkipKinds = []string{"global", "container"}
+
+func parseLayoutSkip(value string) (map[string]bool, error) {
+	skip := map[string]bool{}
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		if !slices.Contains(layoutSkipKinds, kind) {
+			return nil, fmt.Errorf("unknown -layout-skip value %q (%s)", kind, strings.Join(layoutSkipKinds, ", "))
+		}
+		skip[kind] = true
+	}
+	return skip, nil
+}
+
+func layoutBounds(top, left, height, width int) *xmlquery.Node {
+	return newElement("Bounds",
+		"top", strconv.Itoa(top), "left", strconv.Itoa(left),
+		"bottom", strconv.Itoa(top+height), "right", strconv.Itoa(left+width))
+}
+
+// renderLayout は BaseTable のフィールドを定義順に縦に並べたレイアウトオブジェクトの fmxmlsnippet を返す。
+// 各行はフィールド名のテキストとフィールドの組。1 つのスニペットには 1 テーブル分しか入らないので、
+// 複数のテーブルは publish でテーブルごとに分けて呼ぶ（perTableFormats）。
+func renderLayout(s *schema, opts outputOptions) ([]byte, error) {
+	if len(s.Tables) != 1 {
+		return nil, fmt.Errorf("layout output is rendered per table, got %d tables", len(s.Tables))
+	}
+	skip, err := parseLayoutSkip(opts.layoutSkip)
+	if err != nil {
+		return nil, err
+	}
+
+	t := s.Tables[0]
+	rootElement := newElement("fmxmlsnippet", "type", "LayoutObjectList")
+	layoutElement := newElement("Layout")
+	xmlquery.AddChild(rootElement, layoutElement)
+	top := layoutTop
+	key := 0
+	for _, f := range t.Fields {
+		if skip["global"] && isTrue(f.Storage.Global) || skip["container"] && f.DataType == "Binary" {
+			continue
+		}
+		height := layoutRowHeight
+		if f.DataType == "Binary" {
+			height = layoutContainerHeight
+		}
+
+		key++
+		labelElement := newElement("Object", "type", "Text", "key", strconv.Itoa(key), "LabelKey", "0", "flags", "0", "rotation", "0")
+		xmlquery.AddChild(labelElement, layoutBounds(top, layoutLabelLeft, layoutRowHeight, layoutLabelWidth))
+		textElement := newElement("TextObj", "flags", "0")
+		xmlquery.AddChild(textElement, newTextElement("Data", f.Name))
+		xmlquery.AddChild(labelElement, textElement)
+		xmlquery.AddChild(layoutElement, labelElement)
+
+		key++
+		fieldElement := newElement("Object", "type", "Field", "key", strconv.Itoa(key), "LabelKey", strconv.Itoa(key-1), "flags", "0", "rotation", "0")
+		xmlquery.AddChild(fieldElement, layoutBounds(top, layoutFieldLeft, height, layoutFieldWidth))
+		fieldObjElement := newElement("FieldObj", "numOfReps", "1", "flags", "0", "inputMode", "0", "displayType", "0", "quickFind", "1")
+		xmlquery.AddChild(fieldObjElement, newTextElement("Name", t.Name+"::"+f.Name))
+		ddrElement := newElement("DDRInfo")
+		xmlquery.AddChild(ddrElement, newElement("Field",
+			"name", f.Name, "id", f.ID, "repetition", "1", "maxRepetition", f.Storage.MaxRepetition, "table", t.Name))
+		xmlquery.AddChild(fieldObjElement, ddrElement)
+		xmlquery.AddChild(fieldElement, fieldObjElement)
+		xmlquery.AddChild(layoutElement, fieldElement)
+
+		top += height + layoutRowGap
+	}
+	if key == 0 {
+		return nil, fmt.Errorf("%s: no fields to place on the layout", t.Name)
+	}
+	return []byte(rootElement.OutputXML(true)), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestRenderLayout(t *testing.T) {
+	fields := []*field{
+		{ID: "1", Name: "id", DataType: "Text", Storage: storage{MaxRepetition: "1"}},
+		{ID: "2", Name: "photo", DataType: "Binary", Storage: storage{MaxRepetition: "1"}},
+		{ID: "3", Name: "g", DataType: "Text", Storage: storage{Global: "True", MaxRepetition: "1"}},
+	}
+	tests := []struct {
+		name   string
+		skip   string
+		fields []string
+		err    string
+	}{
+		{name: "all", fields: []string{"t::id", "t::photo", "t::g"}},
+		{name: "skip global", skip: "global", fields: []string{"t::id", "t::photo"}},
+		{name: "skip global and container", skip: "global, container", fields: []string{"t::id"}},
+		{name: "unknown kind", skip: "calc", err: `unknown -layout-skip value "calc" (global, container)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &schema{Tables: []*baseTable{{Name: "t", Fields: fields}}}
+			out, err := renderLayout(s, outputOptions{layoutSkip: tt.skip})
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			doc, err := xmlquery.Parse(strings.NewReader(string(out)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, n := range xmlquery.Find(doc, "//FieldObj/Name") {
+				got = append(got, n.InnerText())
+			}
+			if !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %q, want %q", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestPublishPerTable(t *testing.T) {
+	s := &schema{Tables: []*baseTable{{Name: "a"}, {Name: "b"}}}
+	tests := []struct {
+		name     string
+		output   string
+		watching bool
+		paths    []string
+		err      string
+	}{
+		{name: "one file per table", output: "out/layout.xml", paths: []string{"out/layout.a.xml", "out/layout.b.xml"}},
+		{name: "watch with -o", output: "layout.xml", watching: true, paths: []string{"layout.a.xml", "layout.b.xml"}},
+		{name: "watch without -o", watching: true, err: "2 tables: use -o to write one file per table with -watch, or select one with -sheet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			err := publishPerTable(s, tt.output, tt.watching, func(ts *schema, path string) error {
+				if len(ts.Tables) != 1 {
+					t.Errorf("got %d tables, want 1", len(ts.Tables))
+				}
+				paths = append(paths, path)
+				return nil
+			})
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %q, want %q", paths, tt.paths)
+			}
+		})
+	}
+}
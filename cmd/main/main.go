@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -12,10 +14,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/antchfx/xmlquery"
 	"github.com/atotto/clipboard"
 	"github.com/xuri/excelize/v2"
 )
@@ -36,74 +38,103 @@ var cellValueReplacer = strings.NewReplacer(
 )
 
 type fmxmlSnippet struct {
-	XMLName   xml.Name `xml:"fmxmlsnippet"`
-	Type      string   `xml:"type,attr"`
-	BaseTable struct {
-		Name  string `xml:"name,attr"`
-		Field struct {
-			ID          string `xml:"id,attr"`
-			DataType    string `xml:"dataType,attr"`
-			FieldType   string `xml:"fieldType,attr"`
-			Name        string `xml:"name,attr"`
-			Calculation struct {
-				XMLName xml.Name `xml:"Calculation"`
-				Table   string   `xml:"table,attr"`
-				Value   string   `xml:",cdata"`
-			}
-			Comment   string `xml:"Comment"`
-			AutoEnter struct {
-				OverwriteExistingValue string `xml:"overwriteExistingValue,attr"`
-				AlwaysEvaluate         string `xml:"alwaysEvaluate,attr"`
-				AllowEditing           string `xml:"allowEditing,attr"`
-				Constant               string `xml:"constant,attr"`
-				Furigana               string `xml:"furigana,attr"`
-				Lookup                 string `xml:"lookup,attr"`
-				ConstantData           string `xml:"ConstantData"`
-				AutoCalcElement        struct {
-					Table string `xml:"table,attr"`
-					Value string `xml:",chardata"`
-				} `xml:"Calculation"`
-				Serial struct {
-					Increment string `xml:"increment,attr"`
-					NextValue string `xml:"nextValue,attr"`
-					Generate  string `xml:"generate,attr"`
-				} `xml:"Serial"`
-			} `xml:"AutoEnter"`
-			Validation struct {
-				Message                   string `xml:"message,attr"`
-				MaxLength                 string `xml:"maxLength,attr"`
-				Valuelist                 string `xml:"valuelist,attr"`
-				Calculation               string `xml:"calculation,attr"`
-				AlwaysValidateCalculation string `xml:"alwaysValidateCalculation,attr"`
-				Type                      string `xml:"type,attr"`
-				NotEmpty                  struct {
-					Value string `xml:"value,attr"`
-				} `xml:"NotEmpty"`
-				Unique struct {
-					Value string `xml:"value,attr"`
-				} `xml:"Unique"`
-				Existing struct {
-					Value string `xml:"value,attr"`
-				} `xml:"Existing"`
-				MaxDataLength struct {
-					Value string `xml:"value,attr"`
-				} `xml:"MaxDataLength"`
-				StrictDataType struct {
-					Value string `xml:"value,attr"`
-				} `xml:"StrictDataType"`
-				StrictValidation struct {
-					Value string `xml:"value,attr"`
-				} `xml:"StrictValidation"`
-			} `xml:"Validation"`
-			Storage struct {
-				AutoIndex     string `xml:"autoIndex,attr"`
-				Index         string `xml:"index,attr"`
-				IndexLanguage string `xml:"indexLanguage,attr"`
-				Global        string `xml:"global,attr"`
-				MaxRepetition string `xml:"maxRepetition,attr"`
-			} `xml:"Storage"`
-		} `xml:"Field"`
+	XMLName xml.Name `xml:"fmxmlsnippet"`
+	Type    string   `xml:"type,attr"`
+	// config.xml の書式のバージョン（省略時は 1）
+	Version string      `xml:"version,attr"`
+	Sheets  *sheetRules `xml:"Sheets"`
+	// カスタム関数のシートと列（省略時は #FUNCTIONS シートの A〜D 列）
+	CustomFunctions *functionColumns `xml:"CustomFunctions"`
+	// スクリプトシートと列（省略時は #SCRIPT シートの A〜C 列）
+	ScriptSteps *scriptColumns `xml:"ScriptSteps"`
+	// 標準フィールドのシートと追加位置（省略時は #STANDARD シートを先頭に追加）
+	StandardFields *standardRules `xml:"StandardFields"`
+	// フィールド名の命名規則（省略時は FileMaker の制限と予約語のみ検査）
+	NamingRules *namingRules `xml:"NamingRules"`
+	Rows        rowRules     `xml:"Rows"`
+	BaseTable   struct {
+		Name  string       `xml:"name,attr" config:"cell"`
+		Field fieldMapping `xml:"Field"`
 	} `xml:"BaseTable"`
+	// 列のセルが空のときに使う値（省略時は field.defaults の値）
+	Defaults *defaultRules `xml:"Defaults"`
+	// 読み込み中のシートで使う既定値（withDefaults で Defaults から作る）
+	defaults fieldMapping
+}
+
+// fieldMapping は config.xml の Field 要素。各プロパティを読み込む列のセル参照。
+type fieldMapping struct {
+	ID          string `xml:"id,attr" config:"cell"`
+	DataType    string `xml:"dataType,attr" config:"cell"`
+	FieldType   string `xml:"fieldType,attr" config:"cell"`
+	Name        string `xml:"name,attr" config:"cell"`
+	Calculation struct {
+		XMLName xml.Name `xml:"Calculation"`
+		Table   string   `xml:"table,attr" config:"cell"`
+		Value   string   `xml:",cdata" config:"cell"`
+	}
+	Comment   string `xml:"Comment" config:"cell"`
+	AutoEnter struct {
+		OverwriteExistingValue string `xml:"overwriteExistingValue,attr" config:"cell"`
+		AlwaysEvaluate         string `xml:"alwaysEvaluate,attr" config:"cell"`
+		AllowEditing           string `xml:"allowEditing,attr" config:"cell"`
+		Constant               string `xml:"constant,attr" config:"cell"`
+		Furigana               string `xml:"furigana,attr" config:"cell"`
+		Lookup                 string `xml:"lookup,attr" config:"cell"`
+		ConstantData           string `xml:"ConstantData" config:"cell"`
+		AutoCalcElement        struct {
+			Table string `xml:"table,attr" config:"cell"`
+			Value string `xml:",chardata" config:"cell"`
+		} `xml:"Calculation"`
+		Serial struct {
+			Increment string `xml:"increment,attr"`
+			NextValue string `xml:"nextValue,attr" config:"cell"`
+			Generate  string `xml:"generate,attr"`
+		} `xml:"Serial"`
+	} `xml:"AutoEnter"`
+	Validation struct {
+		Message                   string `xml:"message,attr" config:"cell"`
+		MaxLength                 string `xml:"maxLength,attr" config:"cell"`
+		Valuelist                 string `xml:"valuelist,attr" config:"cell"`
+		Calculation               string `xml:"calculation,attr" config:"cell"`
+		AlwaysValidateCalculation string `xml:"alwaysValidateCalculation,attr" config:"cell"`
+		Type                      string `xml:"type,attr" config:"cell"`
+		NotEmpty                  struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"NotEmpty"`
+		Unique struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"Unique"`
+		Existing struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"Existing"`
+		MaxDataLength struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"MaxDataLength"`
+		StrictDataType struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"StrictDataType"`
+		StrictValidation struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"StrictValidation"`
+		Values struct {
+			Value string `xml:"value,attr" config:"cell"`
+		} `xml:"Values"`
+	} `xml:"Validation"`
+	Storage struct {
+		AutoIndex     string `xml:"autoIndex,attr" config:"cell"`
+		Index         string `xml:"index,attr" config:"cell"`
+		IndexLanguage string `xml:"indexLanguage,attr" config:"cell"`
+		Global        string `xml:"global,attr" config:"cell"`
+		MaxRepetition string `xml:"maxRepetition,attr" config:"cell"`
+	} `xml:"Storage"`
+	// アクセス権セットごとのアクセス権の列（省略可、複数指定可）
+	Access []accessMapping `xml:"Access"`
+}
+
+type accessMapping struct {
+	PrivilegeSet string `xml:"privilegeSet,attr"`
+	Value        string `xml:"value,attr" config:"cell"`
 }
 
 func prettyXML(src string) string {
@@ -130,12 +161,17 @@ func prettyXML(src string) string {
 	return buf.String()
 }
 
-func returnCellValue(f *excelize.File, sheetName string, rowAxis int, cellName string, defaultValue string) string {
+// rowCell は config.xml のセル参照の列と、データ行の行番号を組み合わせたセル名を返す。
+func rowCell(cellName string, rowAxis int) string {
+	colAxis, _, _ := excelize.CellNameToCoordinates(cellName)
+	cellLabel, _ := excelize.CoordinatesToCellName(colAxis, rowAxis+1)
+	return cellLabel
+}
+
+func returnCellValue(f sheetReader, sheetName string, rowAxis int, cellName string, defaultValue string) string {
 	var cellValue string
 	if cellName != "" {
-		colAxis, _, _ := excelize.CellNameToCoordinates(cellName)
-		cellLabel, _ := excelize.CoordinatesToCellName(colAxis, rowAxis+1)
-		cellValue, _ = f.GetCellValue(sheetName, cellLabel)
+		cellValue, _ = f.GetCellValue(sheetName, rowCell(cellName, rowAxis))
 	}
 	if cellValue == "" {
 		cellValue = defaultValue
@@ -143,330 +179,319 @@ func returnCellValue(f *excelize.File, sheetName string, rowAxis int, cellName s
 	return cellValueReplacer.Replace(cellValue)
 }
 
+// options は生成に必要な入力の場所と設定。
+type options struct {
+	configPath   string // 空のときは組み込みの設定
+	workbookPath string
+	idStrategy   string
+	sheets       sheetFilter
+	strict       bool // 警告があればエラーにする
+}
+
 func main() {
-	var rec fmxmlSnippet
+	// 警告とエラーは warn / fail が標準エラー出力に書くので、log は -debug の debug.log にだけ書く。
+	// サブコマンドも含めて、既定の出力先（標準エラー出力）に同じ内容が二重に出ないようにする
+	log.SetOutput(io.Discard)
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		if err := runConvert(os.Args[2:]); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-config" {
+		if err := runMigrateConfig(os.Args[2:]); err != nil {
+			fail(err)
+		}
+		return
+	}
 
+	configFlag := flag.String("config", "", "config.xml to use; by default the first config.xml in the workbook's directory, the current directory, the user config directory or the executable's directory, else the built-in one")
 	debug := flag.Bool("debug", false, "write debug.log and output.xml")
+	debugDir := flag.String("debug-dir", "", "directory for debug.log and output.xml (default: the executable's directory)")
+	idStrategy := flag.String("ids", idStrategyExplicit, "field ID strategy: explicit or registry")
+	watch := flag.Bool("watch", false, "regenerate whenever the workbook or config.xml changes")
+	interval := flag.Duration("interval", time.Second, "polling interval for -watch")
+	strict := flag.Bool("strict", false, "treat warnings as errors (exit code 5)")
+	list := flag.Bool("list", false, "list sheets with their table name and field count without generating")
+	format := flag.String("format", "xml", "output format: "+formatNames())
+	dialect := flag.String("dialect", "postgres", "SQL dialect for -format sql: "+dialectNames())
+	goPackage := flag.String("package", "tables", "package name for -format go")
+	layoutSkip := flag.String("layout-skip", "", "field kinds to leave off -format layout, comma separated: "+strings.Join(layoutSkipKinds, ", "))
+	withFunctions := flag.Bool("functions", false, "with -format xml, copy the custom functions to the clipboard first and wait for Enter before copying the tables")
+	scriptStep := flag.String("script-step", "replace", "script step for -format script: replace (Replace Field Contents on all records) or set (Set Field on a new record)")
+	output := flag.String("o", "", "output file; defaults to the input path with the format's extension (xml: clipboard only)")
+	var include, exclude stringList
+	flag.Var(&include, "sheet", "process only sheets matching this glob or /regexp/ (repeatable)")
+	flag.Var(&exclude, "exclude", "skip sheets matching this glob or /regexp/ (repeatable)")
 	flag.Parse()
 
-	exe, err := os.Executable()
-	if err != nil {
-		log.Fatal(err)
+	dir := *debugDir
+	if dir == "" {
+		var err error
+		if dir, err = exeDir(); err != nil {
+			fail(err)
+		}
 	}
-	dir := filepath.Dir(exe)
 
+	// debug.log は -debug のときだけ
 	if *debug {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fail(err)
+		}
 		logFile, err := os.OpenFile(filepath.Join(dir, "debug.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
-			log.Fatal(err)
+			fail(err)
 		}
 		defer logFile.Close()
 		log.SetOutput(logFile)
-	} else {
-		log.SetOutput(io.Discard)
 	}
 
-	r, err := os.Open(filepath.Join(dir, "config.xml"))
+	configPath, err := findConfig(*configFlag, flag.Arg(0))
 	if err != nil {
-		log.Fatal(err)
+		fail(err)
 	}
-	defer r.Close()
-
-	if err = xml.NewDecoder(r).Decode(&rec); err != nil {
-		log.Fatal(err)
+	log.Println("config:", configName(configPath))
+
+	opts := options{
+		configPath:   configPath,
+		workbookPath: flag.Arg(0),
+		idStrategy:   *idStrategy,
+		sheets:       sheetFilter{include: include, exclude: exclude},
+		strict:       *strict,
 	}
-	xlsxFile, err := excelize.OpenFile(flag.Arg(0))
-	if err != nil {
-		log.Fatal(err)
+	if err = opts.sheets.validate(); err != nil {
+		fail(withExitCode(exitUsage, err))
 	}
-	defer xlsxFile.Close()
-
-	_, rowAxis, err := excelize.SplitCellName(rec.BaseTable.Field.ID)
+	outFormat, err := lookupFormat(*format)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	cell := func(sheetName string, rowIndex int, cellName, defaultValue string) string {
-		return returnCellValue(xlsxFile, sheetName, rowIndex, cellName, defaultValue)
+		fail(withExitCode(exitUsage, err))
 	}
-
-	rootElement := &xmlquery.Node{
-		Data: "fmxmlsnippet",
-		Type: xmlquery.ElementNode,
-		Attr: []xmlquery.Attr{
-			{Name: xml.Name{Local: "type"}, Value: "FMObjectList"},
-		},
-	}
-
-	for index, sheetName := range xlsxFile.GetSheetList() {
-		fmt.Println(index+1, sheetName)
-		if sheetName == "#SAMPLE" {
-			continue
+	if *layoutSkip != "" {
+		if *format != "layout" {
+			fail(withExitCode(exitUsage, errors.New("-layout-skip can only be used with -format layout")))
 		}
-		if strings.Contains(sheetName, "#") {
-			continue
+		if _, err = parseLayoutSkip(*layoutSkip); err != nil {
+			fail(withExitCode(exitUsage, err))
 		}
-		rows, err := xlsxFile.GetRows(sheetName)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
-		if len(rows) == 0 {
-			continue
+	}
+	outOpts := outputOptions{dialect: *dialect, goPackage: *goPackage, scriptStep: *scriptStep, layoutSkip: *layoutSkip}
+	publish := func(sc *schema) error {
+		if perTableFormats[*format] && len(sc.Tables) > 1 {
+			return publishPerTable(sc, *output, *watch, func(ts *schema, path string) error {
+				out, err := outFormat.render(ts, outOpts)
+				if err != nil {
+					return withExitCode(exitOutput, err)
+				}
+				if path == "" {
+					return copyToClipboard(string(out), outFormat.clipboard)
+				}
+				if err = os.WriteFile(path, []byte(prettyXML(string(out))), 0644); err != nil {
+					return withExitCode(exitOutput, err)
+				}
+				fmt.Println("wrote", path)
+				return nil
+			})
 		}
 
-		cellValue, _ := xlsxFile.GetCellValue(sheetName, rec.BaseTable.Name)
-		tableElement := &xmlquery.Node{
-			Data: "BaseTable",
-			Type: xmlquery.ElementNode,
-			Attr: []xmlquery.Attr{
-				{Name: xml.Name{Local: "name"}, Value: cellValue},
-			},
+		out, err := outFormat.render(sc, outOpts)
+		if err != nil {
+			return withExitCode(exitOutput, err)
 		}
-
-		fieldXML := rec.BaseTable.Field
-		// trailing empty cells are stripped per row, so row lengths may differ — read cell values directly from sheet
-		for rowIndex, row := range rows {
-			if rowIndex < rowAxis-1 || len(row) <= 1 {
-				continue
-			}
-
-			fieldType := cell(sheetName, rowIndex, fieldXML.FieldType, "Normal")
-			dataType := cell(sheetName, rowIndex, fieldXML.DataType, "Text")
-			if fieldType == "Summary" {
-				dataType = "Number"
+		if outFormat.clipboard == "" {
+			path := *output
+			if path == "" {
+				path = outputPath(opts.workbookPath, outFormat.ext)
 			}
-			fieldElement := &xmlquery.Node{
-				Data: "Field",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{
-					{Name: xml.Name{Local: "id"}, Value: cell(sheetName, rowIndex, fieldXML.ID, strconv.Itoa(rowIndex))},
-					{Name: xml.Name{Local: "name"}, Value: cell(sheetName, rowIndex, fieldXML.Name, fmt.Sprintf("Field#%d", rowIndex))},
-					{Name: xml.Name{Local: "fieldType"}, Value: fieldType},
-					{Name: xml.Name{Local: "dataType"}, Value: dataType},
-				},
+			if err = os.WriteFile(path, out, 0644); err != nil {
+				return withExitCode(exitOutput, err)
 			}
+			fmt.Println("wrote", path)
+			return nil
+		}
 
-			if fieldType == "Summary" {
-				// K列: "Together.Total" など summarizeRepetition.operation 形式
-				parts := strings.SplitN(cell(sheetName, rowIndex, fieldXML.DataType, ""), ".", 2)
-				summarizeRepetition, operation := "Together", ""
-				if len(parts) == 2 {
-					summarizeRepetition, operation = parts[0], parts[1]
-				}
-				// Q列: "id.name" 形式の SummaryField 参照
-				refParts := strings.SplitN(cell(sheetName, rowIndex, fieldXML.Calculation.Value, ""), ".", 2)
-				fieldID, fieldName := "", ""
-				if len(refParts) == 2 {
-					fieldID, fieldName = refParts[0], refParts[1]
-				}
-				summaryInfoElement := &xmlquery.Node{
-					Data: "SummaryInfo",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{
-						{Name: xml.Name{Local: "restartForEachSortedGroup"}, Value: "False"},
-						{Name: xml.Name{Local: "summarizeRepetition"}, Value: summarizeRepetition},
-						{Name: xml.Name{Local: "operation"}, Value: operation},
-					},
-				}
-				summaryFieldElement := &xmlquery.Node{Data: "SummaryField", Type: xmlquery.ElementNode}
-				xmlquery.AddChild(summaryFieldElement, &xmlquery.Node{
-					Data: "Field",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{
-						{Name: xml.Name{Local: "id"}, Value: fieldID},
-						{Name: xml.Name{Local: "name"}, Value: fieldName},
-					},
-				})
-				xmlquery.AddChild(summaryInfoElement, summaryFieldElement)
-				xmlquery.AddChild(fieldElement, summaryInfoElement)
+		if *withFunctions && *format == "xml" && len(sc.Functions) > 0 {
+			fn, err := renderFunctionSnippet(sc, outOpts)
+			if err == nil {
+				err = copyToClipboard(string(fn), outputFormats["functions"].clipboard)
 			}
-
-			commentElement := &xmlquery.Node{Data: "Comment", Type: xmlquery.ElementNode}
-			xmlquery.AddChild(commentElement, &xmlquery.Node{
-				Data: cell(sheetName, rowIndex, fieldXML.Comment, ""),
-				Type: xmlquery.TextNode,
-			})
-			xmlquery.AddChild(fieldElement, commentElement)
-
-			if fieldType == "Calculated" {
-				calcElement := &xmlquery.Node{
-					Data: "Calculation",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{
-						{Name: xml.Name{Local: "table"}, Value: cell(sheetName, rowIndex, fieldXML.Calculation.Table, "")},
-					},
-				}
-				xmlquery.AddChild(calcElement, &xmlquery.Node{
-					Data: cell(sheetName, rowIndex, fieldXML.Calculation.Value, ""),
-					Type: xmlquery.TextNode,
-				})
-				xmlquery.AddChild(fieldElement, calcElement)
+			if err != nil {
+				return withExitCode(exitOutput, fmt.Errorf("copy custom functions: %w", err))
 			}
+			fmt.Printf("copied %d custom functions; paste them in Manage Custom Functions, then press Enter to copy the tables\n", len(sc.Functions))
+			bufio.NewReader(os.Stdin).ReadString('\n')
+		}
 
-			autoEnterElement := &xmlquery.Node{
-				Data: "AutoEnter",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{
-					{Name: xml.Name{Local: "constant"}, Value: "False"},
-					{Name: xml.Name{Local: "calculation"}, Value: "False"},
-					{Name: xml.Name{Local: "alwaysEvaluate"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.AlwaysEvaluate, "False")},
-					{Name: xml.Name{Local: "overwriteExistingValue"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.OverwriteExistingValue, "False")},
-					{Name: xml.Name{Local: "allowEditing"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.AllowEditing, "True")},
-					{Name: xml.Name{Local: "furigana"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.Furigana, "False")},
-					{Name: xml.Name{Local: "lookup"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.Lookup, "False")},
-				},
+		xmlStr := string(out)
+		if *debug {
+			if err := os.WriteFile(filepath.Join(dir, "output.xml"), []byte(prettyXML(xmlStr)), 0644); err != nil {
+				warn("%v", err)
 			}
-
-			constantDataElement := &xmlquery.Node{Data: "ConstantData", Type: xmlquery.ElementNode}
-			textCellRef := fieldXML.AutoEnter.ConstantData
-			isSerial := false
-			switch autoEnterConstant := cell(sheetName, rowIndex, fieldXML.AutoEnter.Constant, ""); autoEnterConstant {
-			case "固定値":
-				autoEnterElement.SetAttr("constant", "True")
-			case "作成TS":
-				autoEnterElement.SetAttr("value", "CreationTimeStamp")
-			case "作成者":
-				autoEnterElement.SetAttr("value", "CreationAccountName")
-			case "修正TS":
-				autoEnterElement.SetAttr("value", "ModificationTimeStamp")
-			case "修正者":
-				autoEnterElement.SetAttr("value", "ModificationAccountName")
-			case "計算値":
-				autoEnterElement.SetAttr("calculation", "True")
-				textCellRef = fieldXML.AutoEnter.AutoCalcElement.Value
-				constantDataElement = &xmlquery.Node{
-					Data: "Calculation",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{
-						{Name: xml.Name{Local: "table"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.AutoCalcElement.Table, "")},
-					},
-				}
-			case "シリアル番号":
-				isSerial = true
-				xmlquery.AddChild(autoEnterElement, &xmlquery.Node{
-					Data: "Serial",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{
-						{Name: xml.Name{Local: "increment"}, Value: fieldXML.AutoEnter.Serial.Increment},
-						{Name: xml.Name{Local: "nextValue"}, Value: cell(sheetName, rowIndex, fieldXML.AutoEnter.Serial.NextValue, "")},
-						{Name: xml.Name{Local: "generate"}, Value: fieldXML.AutoEnter.Serial.Generate},
-					},
-				})
+		}
+		if *output != "" {
+			if err := os.WriteFile(*output, []byte(prettyXML(xmlStr)), 0644); err != nil {
+				return withExitCode(exitOutput, err)
 			}
-			if !isSerial {
-				xmlquery.AddChild(constantDataElement, &xmlquery.Node{
-					Data: cell(sheetName, rowIndex, textCellRef, ""),
-					Type: xmlquery.TextNode,
-				})
-				xmlquery.AddChild(autoEnterElement, constantDataElement)
+		}
+		if err := copyToClipboard(xmlStr, outFormat.clipboard); err != nil {
+			// -o のファイルは書き出せているので、クリップボードを使えない環境でも失敗にはしない
+			if *output != "" {
+				warn("copy to clipboard: %v (wrote %s)", err, *output)
+				return nil
 			}
-			xmlquery.AddChild(fieldElement, autoEnterElement)
+			return withExitCode(exitOutput, fmt.Errorf("copy to clipboard: %w", err))
+		}
+		return nil
+	}
 
-			// 値を先にすべて読み込む
-			strictDataTypeValue := cell(sheetName, rowIndex, fieldXML.Validation.StrictDataType.Value, "")
-			uniqueValue := cell(sheetName, rowIndex, fieldXML.Validation.Unique.Value, "False")
-			notEmptyValue := cell(sheetName, rowIndex, fieldXML.Validation.NotEmpty.Value, "False")
-			maxLengthValue := cell(sheetName, rowIndex, fieldXML.Validation.MaxDataLength.Value, "")
-			existingValue := cell(sheetName, rowIndex, fieldXML.Validation.Existing.Value, "False")
-			strictValidationValue := cell(sheetName, rowIndex, fieldXML.Validation.StrictValidation.Value, "")
+	if *list {
+		if err = listSheets(opts); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if *watch {
+		if *withFunctions {
+			fail(withExitCode(exitUsage, errors.New("-functions cannot be used with -watch")))
+		}
+		watchFiles(opts, *interval, publish)
+		return
+	}
 
-			// StrictDataType が設定されている場合、StrictValidation のデフォルトは True
-			if strictDataTypeValue != "" && strictValidationValue == "" {
-				strictValidationValue = "True"
-			}
-			if strings.EqualFold(strictValidationValue, "True") {
-				strictValidationValue = "True"
-			} else {
-				strictValidationValue = "False"
-			}
+	sc, err := loadSchema(opts)
+	if err != nil {
+		fail(err)
+	}
+	if err = publish(sc); err != nil {
+		fail(err)
+	}
+}
 
-			validationElement := &xmlquery.Node{
-				Data: "Validation",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{
-					{Name: xml.Name{Local: "maxLength"}, Value: map[bool]string{true: "True", false: "False"}[maxLengthValue != ""]},
-					{Name: xml.Name{Local: "message"}, Value: cell(sheetName, rowIndex, fieldXML.Validation.Message, "False")},
-					{Name: xml.Name{Local: "valuelist"}, Value: cell(sheetName, rowIndex, fieldXML.Validation.Valuelist, "False")},
-					{Name: xml.Name{Local: "calculation"}, Value: cell(sheetName, rowIndex, fieldXML.Validation.Calculation, "False")},
-					{Name: xml.Name{Local: "alwaysValidateCalculation"}, Value: cell(sheetName, rowIndex, fieldXML.Validation.AlwaysValidateCalculation, "False")},
-					{Name: xml.Name{Local: "type"}, Value: cell(sheetName, rowIndex, "", "OnlyDuringDataEntry")},
-				},
-			}
+// loadConfig は config.xml を読み込んで検証する。エラーには終了コード exitConfig が付く。
+func loadConfig(configPath string) (fmxmlSnippet, error) {
+	rec, err := readConfig(configPath)
+	return rec, withExitCode(exitConfig, err)
+}
 
-			// 列順に追加: タイプ → ユニーク → 空欄不可 → 文字制限 → 既存値 → 上書き
-			if strictDataTypeValue != "" {
-				xmlquery.AddChild(validationElement, &xmlquery.Node{
-					Data: "StrictDataType",
-					Type: xmlquery.ElementNode,
-					Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: strictDataTypeValue}},
-				})
-			}
-			xmlquery.AddChild(validationElement, &xmlquery.Node{
-				Data: "Unique",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: uniqueValue}},
-			})
-			xmlquery.AddChild(validationElement, &xmlquery.Node{
-				Data: "NotEmpty",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: notEmptyValue}},
-			})
-			xmlquery.AddChild(validationElement, &xmlquery.Node{
-				Data: "MaxDataLength",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: maxLengthValue}},
-			})
-			xmlquery.AddChild(validationElement, &xmlquery.Node{
-				Data: "Existing",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: existingValue}},
-			})
-			xmlquery.AddChild(validationElement, &xmlquery.Node{
-				Data: "StrictValidation",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: strictValidationValue}},
-			})
-			xmlquery.AddChild(fieldElement, validationElement)
+// readConfig は configPath が空のときは組み込みの設定を読み込む。
+func readConfig(configPath string) (fmxmlSnippet, error) {
+	var rec fmxmlSnippet
 
-			xmlquery.AddChild(fieldElement, &xmlquery.Node{
-				Data: "Storage",
-				Type: xmlquery.ElementNode,
-				Attr: []xmlquery.Attr{
-					{Name: xml.Name{Local: "autoIndex"}, Value: cell(sheetName, rowIndex, fieldXML.Storage.AutoIndex, "True")},
-					{Name: xml.Name{Local: "index"}, Value: cell(sheetName, rowIndex, fieldXML.Storage.Index, "None")},
-					{Name: xml.Name{Local: "indexLanguage"}, Value: cell(sheetName, rowIndex, fieldXML.Storage.IndexLanguage, "Japanese")},
-					{Name: xml.Name{Local: "global"}, Value: cell(sheetName, rowIndex, fieldXML.Storage.Global, "False")},
-					{Name: xml.Name{Local: "maxRepetition"}, Value: cell(sheetName, rowIndex, fieldXML.Storage.MaxRepetition, "1")},
-				},
-			})
-			xmlquery.AddChild(tableElement, fieldElement)
+	data := defaultConfig
+	if configPath != "" {
+		var err error
+		if data, err = os.ReadFile(configPath); err != nil {
+			return rec, err
 		}
-		xmlquery.AddChild(rootElement, tableElement)
 	}
+	name := configName(configPath)
 
-	xmlStr := rootElement.OutputXML(true)
+	warnings, err := checkConfig(data, name)
+	for _, w := range warnings {
+		warn("%s", w)
+	}
+	if err != nil {
+		return rec, err
+	}
+	if err := xml.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("%s: %w", name, err)
+	}
+	if _, err := rec.dataRange(); err != nil {
+		return rec, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := rec.sheetFilter().validate(); err != nil {
+		return rec, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := rec.namingRules().validate(); err != nil {
+		return rec, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := rec.Defaults.validate(); err != nil {
+		return rec, fmt.Errorf("%s: %w", name, err)
+	}
+	return rec, nil
+}
 
-	if *debug {
-		if err = os.WriteFile(filepath.Join(dir, "output.xml"), []byte(prettyXML(xmlStr)), 0644); err != nil {
-			log.Println(err)
+func exeDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(exe), nil
+}
+
+// loadSchema は入力の種類（ワークブック・CSV/TSV・YAML/JSON）に応じてテーブル定義を読み込み、ID と集計対象を解決する。
+func loadSchema(opts options) (*schema, error) {
+	// YAML / JSON の入力でも、命名規則などワークブックの列によらない設定は config.xml から読む
+	rec, err := loadConfig(opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+	// YAML / JSON の空のプロパティと convert で省略する値は、#CONFIG シートではなく config.xml の <Defaults> の値
+	def, err := rec.schemaFileDefaults()
+	var s *schema
+	if isSchemaFile(opts.workbookPath) {
+		if err != nil {
+			return nil, withExitCode(exitConfig, fmt.Errorf("%s: %w", configName(opts.configPath), err))
+		}
+		if s, err = readSchemaFile(opts.workbookPath, opts.sheets, def); err != nil {
+			return nil, withExitCode(exitInput, err)
+		}
+	} else {
+		book, err := openSheetReader(opts.workbookPath)
+		if err != nil {
+			return nil, withExitCode(exitInput, err)
+		}
+		defer book.Close()
+
+		if rec, err = applyConfigSheet(book, rec); err != nil {
+			return nil, withExitCode(exitConfig, err)
+		}
+		if s, err = parseWorkbook(book, rec, opts.sheets); err != nil {
+			return nil, withExitCode(exitInput, err)
 		}
 	}
+	s.defaults = def
 
+	var registry *idRegistry
+	if opts.idStrategy == idStrategyRegistry {
+		if registry, err = loadIDRegistry(opts.workbookPath); err != nil {
+			return nil, withExitCode(exitInput, err)
+		}
+	}
+	summaryWarnings, err := prepareSchema(s, opts.idStrategy, registry)
+	if err != nil {
+		return nil, withExitCode(exitInput, err)
+	}
+	nameWarnings, err := lintNames(s, rec.namingRules())
+	if err != nil {
+		return nil, withExitCode(exitLint, err)
+	}
+	warnings := slices.Concat(summaryWarnings, lintCalculations(s), lintFieldRules(s), nameWarnings)
+	for _, w := range warnings {
+		warn("%s", w)
+	}
+	if opts.strict && len(warnings) > 0 {
+		return nil, withExitCode(exitLint, fmt.Errorf("%d warnings (-strict)", len(warnings)))
+	}
+	if registry != nil {
+		if err := registry.save(); err != nil {
+			return nil, withExitCode(exitOutput, err)
+		}
+	}
+	return s, nil
+}
+
+// copyToClipboard は XML を FileMaker が貼り付けられる形でクリップボードに置く。dataType は macOS での型（XMTB, XMSS など）。
+func copyToClipboard(xmlStr, dataType string) error {
 	switch runtime.GOOS {
 	case "darwin":
 		// https://stackoverflow.com/questions/45248144
 		// Pass script via stdin to avoid ARG_MAX limit with large XML payloads.
 		darwinCmd := exec.Command("/usr/bin/osascript")
-		darwinCmd.Stdin = strings.NewReader(fmt.Sprintf(`set the clipboard to «data XMTB%s»`, hex.EncodeToString([]byte(xmlStr))))
-		if err = darwinCmd.Run(); err != nil {
-			log.Println(err)
-		}
+		darwinCmd.Stdin = strings.NewReader(fmt.Sprintf(`set the clipboard to «data %s%s»`, dataType, hex.EncodeToString([]byte(xmlStr))))
+		return darwinCmd.Run()
 	case "windows":
-		if err = clipboard.WriteAll(xmlStr); err != nil {
-			log.Println(err)
-		}
+		return clipboard.WriteAll(xmlStr)
 	default:
-		log.Println("unsupported OS")
+		return errors.New("unsupported OS")
 	}
 }
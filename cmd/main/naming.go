@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxNameLength = 100 // FileMaker のフィールド名の最大文字数
+	// 計算式で引用符なしに参照できなくなる文字（演算子・区切り文字）
+	defaultForbiddenChars = `+-*/^&=≠<>≤≥()[]{}";,`
+)
+
+// namingRules は config.xml の <NamingRules>。フィールド名の命名規則。
+type namingRules struct {
+	MaxLength string        `xml:"maxLength,attr"` // 最大文字数（省略時は 100）
+	Forbidden *string       `xml:"forbidden,attr"` // 使えない文字（省略時は defaultForbiddenChars）
+	Severity  string        `xml:"severity,attr"`  // warning（既定）/ error
+	Rules     []namePattern `xml:"Rule"`
+	Reserved  []string      `xml:"Reserved"` // 組み込み関数・演算子（and, or, xor, not）・カスタム関数の名前に加えて使えない名前
+}
+
+// namePattern は fieldType と global で絞り込んだフィールドの名前が一致しなければならない正規表現。
+type namePattern struct {
+	FieldType string `xml:"fieldType,attr"` // Normal / Calculated / Summary（通常タイプなども可）、省略時はすべて
+	Global    string `xml:"global,attr"`    // True / False、省略時はどちらも
+	Pattern   string `xml:"pattern,attr"`
+}
+
+func (rec fmxmlSnippet) namingRules() namingRules {
+	if rec.NamingRules == nil {
+		return namingRules{}
+	}
+	return *rec.NamingRules
+}
+
+// validate は最大文字数・重大度・正規表現の書式を事前に検証する。
+func (rules namingRules) validate() error {
+	if rules.MaxLength != "" {
+		if n, err := strconv.Atoi(rules.MaxLength); err != nil || n <= 0 {
+			return fmt.Errorf("NamingRules maxLength %q must be a positive number", rules.MaxLength)
+		}
+	}
+	if rules.Severity != "" && rules.Severity != "warning" && rules.Severity != "error" {
+		return fmt.Errorf("unknown NamingRules severity %q (warning or error)", rules.Severity)
+	}
+	for _, r := range rules.Rules {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("NamingRules pattern %q: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
+
+func (rules namingRules) maxLength() int {
+	if n, err := strconv.Atoi(rules.MaxLength); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxNameLength
+}
+
+func (rules namingRules) forbidden() string {
+	if rules.Forbidden == nil {
+		return defaultForbiddenChars
+	}
+	return *rules.Forbidden
+}
+
+func (p namePattern) appliesTo(f *field) bool {
+	if p.FieldType != "" && cellValueReplacer.Replace(p.FieldType) != f.FieldType {
+		return false
+	}
+	return p.Global == "" || isTrue(p.Global) == isTrue(f.Storage.Global)
+}
+
+// lintNames はフィールド名の命名規則の違反を返す。severity が error のときは違反をまとめてエラーにする。
+func lintNames(s *schema, rules namingRules) ([]string, error) {
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	reserved := newNameSet(rules.Reserved...)
+	for name := range builtinFunctions {
+		reserved.add(name)
+	}
+	for _, fn := range s.Functions {
+		reserved.add(fn.Name)
+	}
+	patterns := make([]*regexp.Regexp, len(rules.Rules))
+	for i, r := range rules.Rules {
+		patterns[i] = regexp.MustCompile(r.Pattern)
+	}
+	maxLength, forbidden := rules.maxLength(), rules.forbidden()
+
+	var violations []string
+	for _, t := range s.Tables {
+		for _, f := range t.Fields {
+			violate := func(format string, args ...any) {
+				violations = append(violations, fmt.Sprintf("%s: field %q: ", f.nameCell(), f.Name)+fmt.Sprintf(format, args...))
+			}
+			if n := utf8.RuneCountInString(f.Name); n > maxLength {
+				violate("name is %d characters long (max %d)", n, maxLength)
+			}
+			if strings.Contains(f.Name, "::") {
+				violate(`name contains "::"`)
+			}
+			if r, _ := utf8.DecodeRuneInString(f.Name); unicode.IsDigit(r) {
+				violate("name starts with a digit")
+			}
+			if i := strings.IndexAny(f.Name, forbidden); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(f.Name[i:])
+				violate("name contains forbidden character %q", r)
+			}
+			if reserved.has(f.Name) {
+				violate("name is a reserved word or function name")
+			}
+			for i, r := range rules.Rules {
+				if r.appliesTo(f) && !patterns[i].MatchString(f.Name) {
+					violate("name does not match %s", r.Pattern)
+				}
+			}
+		}
+	}
+	if rules.Severity == "error" && len(violations) > 0 {
+		return nil, errors.New(strings.Join(violations, "\n"))
+	}
+	return violations, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules namingRules
+		field field
+		want  []string
+	}{
+		{
+			name:  "valid",
+			field: field{Name: "name", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+		},
+		{
+			name:  "forbidden character at the name cell",
+			field: field{Name: "a/b", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "a/b": name contains forbidden character '/'`},
+		},
+		{
+			name:  "starts with a digit without a name origin",
+			field: field{Name: "1st", FieldType: "Normal", origin: "book.yaml: t.fields[0]"},
+			want:  []string{`book.yaml: t.fields[0]: field "1st": name starts with a digit`},
+		},
+		{
+			name:  "reserved function name",
+			field: field{Name: "Left", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "Left": name is a reserved word or function name`},
+		},
+		{
+			name:  "operator",
+			field: field{Name: "AND", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "AND": name is a reserved word or function name`},
+		},
+		{
+			name:  "operator in mixed case",
+			field: field{Name: "Xor", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "Xor": name is a reserved word or function name`},
+		},
+		{
+			name:  "operator as part of a name",
+			field: field{Name: "nota", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+		},
+		{
+			name:  "reserved word from the config",
+			rules: namingRules{Reserved: []string{"id"}},
+			field: field{Name: "ID", FieldType: "Normal", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "ID": name is a reserved word or function name`},
+		},
+		{
+			name:  "pattern",
+			rules: namingRules{Rules: []namePattern{{FieldType: "Calculated", Pattern: "^c_"}}},
+			field: field{Name: "total", FieldType: "Calculated", origin: "t!A10", nameOrigin: "t!C10"},
+			want:  []string{`t!C10: field "total": name does not match ^c_`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{&tt.field}}}}
+			got, err := lintNames(s, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
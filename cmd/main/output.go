@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// outputOptions は出力形式ごとの設定。
+type outputOptions struct {
+	dialect    string // sql の方言
+	goPackage  string // go のパッケージ名
+	scriptStep string // script で使うスクリプトステップ（replace または set）
+	layoutSkip string // layout で配置しないフィールドの種類（カンマ区切り）
+}
+
+// outputFormat は -format で選べる出力形式。
+// clipboard があるものは FileMaker に貼り付ける形式で、ファイルではなくクリップボードへ出力する。
+type outputFormat struct {
+	ext       string
+	clipboard string // macOS のクリップボードの型（XMTB: テーブル, XMSS: スクリプトステップ, XMFN: カスタム関数, XML2: レイアウトオブジェクト）
+	render    func(s *schema, opts outputOptions) ([]byte, error)
+}
+
+var outputFormats = map[string]outputFormat{
+	"xml":        {".xml", "XMTB", renderTableSnippet},
+	"script":     {".xml", "XMSS", renderScript},
+	"functions":  {".xml", "XMFN", renderFunctionSnippet},
+	"layout":     {".xml", "XML2", renderLayout},
+	"markdown":   {".md", "", renderMarkdown},
+	"html":       {".html", "", renderHTML},
+	"sql":        {".sql", "", renderSQL},
+	"jsonschema": {".schema.json", "", renderJSONSchema},
+	"openapi":    {".openapi.json", "", renderOpenAPI},
+	"go":         {".go", "", renderGo},
+	"access":     {".access.md", "", renderAccessReport},
+}
+
+// perTableFormats は 1 つのスニペットに 1 テーブル分しか入らない形式。テーブルごとに出力する。
+var perTableFormats = map[string]bool{"layout": true}
+
+// formatNames は -format に指定できる値の一覧。
+func formatNames() string {
+	var names []string
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+func lookupFormat(name string) (outputFormat, error) {
+	f, ok := outputFormats[name]
+	if !ok {
+		return f, fmt.Errorf("unknown format %q (%s)", name, formatNames())
+	}
+	return f, nil
+}
+
+// outputPath は -o を省略したときの出力先。入力と同じ場所に拡張子を変えて書き出す。
+func outputPath(inputPath, ext string) string {
+	base := filepath.Clean(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + ext
+}
+
+// tableOutputPath はテーブルごとに出力するときの出力先（out.xml → out.テーブル名.xml）。
+func tableOutputPath(path, tableName string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + tableName + ext
+}
+
+// publishPerTable は perTableFormats の形式をテーブルごとに publish に渡す。-o があれば out.テーブル名.xml に書き出し、
+// なければ 1 テーブルずつクリップボードにコピーして、貼り付けて Enter を押すまで次のテーブルを待つ。
+func publishPerTable(s *schema, output string, watching bool, publish func(ts *schema, path string) error) error {
+	if output == "" && watching {
+		return withExitCode(exitUsage, fmt.Errorf("%d tables: use -o to write one file per table with -watch, or select one with -sheet", len(s.Tables)))
+	}
+	for i, t := range s.Tables {
+		ts := &schema{Tables: []*baseTable{t}, Functions: s.Functions}
+		if output != "" {
+			if err := publish(ts, tableOutputPath(output, t.Name)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := publish(ts, ""); err != nil {
+			return withExitCode(exitOutput, fmt.Errorf("copy %s to clipboard: %w", t.Name, err))
+		}
+		if i == len(s.Tables)-1 {
+			fmt.Printf("copied %s\n", t.Name)
+			break
+		}
+		fmt.Printf("copied %s; paste it, then press Enter to copy %s\n", t.Name, s.Tables[i+1].Name)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// sheetReader は定義の読み込み元。xlsx のほか、CSV/TSV のディレクトリや ODS を同じ config.xml の列割り当てで読む。
+// GetRows は excelize と同じく、各行の末尾の空セルと末尾の空行を含まない。
+type sheetReader interface {
+	GetSheetList() []string
+	GetRows(sheetName string) ([][]string, error)
+	GetCellValue(sheetName, cell string) (string, error)
+	Close() error
+}
+
+// openSheetReader は拡張子（ディレクトリの場合は中の CSV/TSV）から読み込み元を選ぶ。
+func openSheetReader(path string) (sheetReader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return openCSVDir(path)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv":
+		return openCSVFiles([]string{path})
+	case ".ods":
+		return openODS(path)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return xlsxReader{f}, nil
+}
+
+type xlsxReader struct {
+	*excelize.File
+}
+
+func (r xlsxReader) GetRows(sheetName string) ([][]string, error) {
+	return r.File.GetRows(sheetName)
+}
+
+func (r xlsxReader) GetCellValue(sheetName, cell string) (string, error) {
+	return r.File.GetCellValue(sheetName, cell)
+}
+
+// gridReader はメモリ上に読み込んだシート（CSV/TSV, ODS）。
+type gridReader struct {
+	names  []string
+	sheets map[string][][]string
+}
+
+func newGridReader() *gridReader {
+	return &gridReader{sheets: map[string][][]string{}}
+}
+
+func (r *gridReader) add(sheetName string, rows [][]string) {
+	for i, row := range rows {
+		rows[i] = row[:lastNonEmpty(row)]
+	}
+	r.names = append(r.names, sheetName)
+	r.sheets[sheetName] = rows[:lastNonEmpty(rows)]
+}
+
+func lastNonEmpty[T string | []string](values []T) int {
+	n := len(values)
+	for n > 0 && len(values[n-1]) == 0 {
+		n--
+	}
+	return n
+}
+
+func (r *gridReader) GetSheetList() []string {
+	return slices.Clone(r.names)
+}
+
+func (r *gridReader) GetRows(sheetName string) ([][]string, error) {
+	rows, ok := r.sheets[sheetName]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s does not exist", sheetName)
+	}
+	return rows, nil
+}
+
+func (r *gridReader) GetCellValue(sheetName, cell string) (string, error) {
+	rows, err := r.GetRows(sheetName)
+	if err != nil {
+		return "", err
+	}
+	col, row, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return "", err
+	}
+	if row > len(rows) || col > len(rows[row-1]) {
+		return "", nil
+	}
+	return rows[row-1][col-1], nil
+}
+
+func (r *gridReader) Close() error {
+	return nil
+}
+
+// openCSVDir はディレクトリ内の *.csv / *.tsv を 1 ファイル 1 シートとして読む。シート名は拡張子を除いたファイル名。
+func openCSVDir(dir string) (sheetReader, error) {
+	paths, err := csvDirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%s: no CSV or TSV files", dir)
+	}
+	return openCSVFiles(paths)
+}
+
+// csvDirFiles はディレクトリ内の *.csv / *.tsv をファイル名順に返す。
+func csvDirFiles(dir string) ([]string, error) {
+	var paths []string
+	for _, pattern := range []string{"*.csv", "*.tsv"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func openCSVFiles(paths []string) (sheetReader, error) {
+	r := newGridReader()
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Excel で保存した CSV は Shift_JIS のことがあるので、UTF-8 として不正なら変換する
+		b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(b) {
+			if b, err = japanese.ShiftJIS.NewDecoder().Bytes(b); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		cr := csv.NewReader(bytes.NewReader(b))
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		if strings.EqualFold(filepath.Ext(path), ".tsv") {
+			cr.Comma = '\t'
+		}
+		rows, err := readCSVRows(cr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		r.add(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), rows)
+	}
+	return r, nil
+}
+
+// readCSVRows は CSV を行番号どおりに読む。encoding/csv は空行を読み飛ばすので、行番号の差分から空行を補う。
+func readCSVRows(cr *csv.Reader) ([][]string, error) {
+	var rows [][]string
+	nextLine := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		for ; nextLine < line; nextLine++ {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, record)
+		// 引用符内の改行を含むレコードは複数行にまたがる
+		nextLine = line + 1
+		for _, field := range record {
+			nextLine += strings.Count(field, "\n")
+		}
+	}
+}
+
+// maxRepeat は ODS の number-rows-repeated / number-columns-repeated で値を複製する上限。
+// 空のセル・行の繰り返し（シート末尾までの書式など）は複製せずに数えるだけにする。
+const maxRepeat = 1000
+
+// openODS は OpenDocument スプレッドシートの content.xml からセルの表示値を読む。
+func openODS(path string) (sheetReader, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var content io.ReadCloser
+	for _, f := range zr.File {
+		if f.Name == "content.xml" {
+			if content, err = f.Open(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%s: content.xml not found", path)
+	}
+	defer content.Close()
+
+	r := newGridReader()
+	var (
+		sheetName             string
+		rows                  [][]string
+		row                   []string
+		rowRepeat, cellRepeat int
+		emptyRows, emptyCells int
+		cellText              strings.Builder
+		inCell, inParagraph   bool
+		paragraphs            int
+		annotations           int // コメント（office:annotation）の中では段落を読まない
+	)
+	repeat := func(e xml.StartElement, name string) int {
+		for _, a := range e.Attr {
+			if a.Name.Local == name {
+				if n, err := strconv.Atoi(a.Value); err == nil && n > 0 {
+					return n
+				}
+			}
+		}
+		return 1
+	}
+
+	dec := xml.NewDecoder(content)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "table":
+				for _, a := range t.Attr {
+					if a.Name.Local == "name" {
+						sheetName = a.Value
+					}
+				}
+				rows, emptyRows = nil, 0
+			case "table-row":
+				row, emptyCells = nil, 0
+				rowRepeat = repeat(t, "number-rows-repeated")
+			case "table-cell", "covered-table-cell":
+				inCell, paragraphs = true, 0
+				cellText.Reset()
+				cellRepeat = repeat(t, "number-columns-repeated")
+			case "annotation":
+				annotations++
+			case "p":
+				if inCell && annotations == 0 {
+					if paragraphs > 0 {
+						cellText.WriteByte('\n')
+					}
+					inParagraph = true
+					paragraphs++
+				}
+			case "s":
+				if inParagraph {
+					cellText.WriteString(strings.Repeat(" ", repeat(t, "c")))
+				}
+			case "tab":
+				if inParagraph {
+					cellText.WriteByte('\t')
+				}
+			case "line-break":
+				if inParagraph {
+					cellText.WriteByte('\n')
+				}
+			}
+		case xml.CharData:
+			if inParagraph {
+				cellText.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "annotation":
+				annotations--
+			case "p":
+				inParagraph = false
+			case "table-cell", "covered-table-cell":
+				inCell = false
+				if value := cellText.String(); value == "" {
+					emptyCells += cellRepeat
+				} else {
+					for ; emptyCells > 0; emptyCells-- {
+						row = append(row, "")
+					}
+					for i := 0; i < min(cellRepeat, maxRepeat); i++ {
+						row = append(row, value)
+					}
+				}
+			case "table-row":
+				if len(row) == 0 {
+					emptyRows += rowRepeat
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					rows = append(rows, nil)
+				}
+				for i := 0; i < min(rowRepeat, maxRepeat); i++ {
+					rows = append(rows, slices.Clone(row))
+				}
+			case "table":
+				r.add(sheetName, rows)
+			}
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVRows(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want [][]string
+	}{
+		{
+			name: "rows",
+			csv:  "a,b\nc\n",
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "empty lines",
+			csv:  "\n\na\n\nb\n",
+			want: [][]string{nil, nil, {"a"}, nil, {"b"}},
+		},
+		{
+			name: "quoted newline",
+			csv:  "\"a\nb\",c\n\nd\n",
+			want: [][]string{{"a\nb", "c"}, nil, {"d"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := csv.NewReader(strings.NewReader(tt.csv))
+			cr.FieldsPerRecord = -1
+			got, err := readCSVRows(cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeODS は content.xml の table:table 要素だけを持つ ODS ファイルを作る。
+func writeODS(t *testing.T, tables string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "book.ods")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	w, err := zw.Create("content.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<office:body><office:spreadsheet>` + tables + `</office:spreadsheet></office:body></office:document-content>`
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenODS(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		want  [][]string
+	}{
+		{
+			name:  "cells",
+			table: `<table:table-row><table:table-cell><text:p>a</text:p></table:table-cell><table:table-cell/><table:table-cell><text:p>b</text:p></table:table-cell></table:table-row>`,
+			want:  [][]string{{"a", "", "b"}},
+		},
+		{
+			name:  "repeated",
+			table: `<table:table-row table:number-rows-repeated="2"/><table:table-row><table:table-cell table:number-columns-repeated="2"><text:p>x</text:p></table:table-cell></table:table-row>`,
+			want:  [][]string{nil, nil, {"x", "x"}},
+		},
+		{
+			name:  "paragraphs and spaces",
+			table: `<table:table-row><table:table-cell><text:p>a<text:s text:c="2"/>b</text:p><text:p>c</text:p></table:table-cell></table:table-row>`,
+			want:  [][]string{{"a  b\nc"}},
+		},
+		{
+			name:  "annotation",
+			table: `<table:table-row><table:table-cell><office:annotation><dc:creator>me</dc:creator><text:p>note</text:p></office:annotation><text:p>value</text:p></table:table-cell><table:table-cell><office:annotation><text:p>only a note</text:p></office:annotation></table:table-cell><table:table-cell><text:p>c</text:p></table:table-cell></table:table-row>`,
+			want:  [][]string{{"value", "", "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeODS(t, `<table:table table:name="s">`+tt.table+`</table:table>`)
+			book, err := openODS(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer book.Close()
+			got, err := book.GetRows("s")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
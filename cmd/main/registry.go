@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// フィールド ID の決め方
+const (
+	idStrategyExplicit = "explicit" // ID 列の値を使い、空なら行番号
+	idStrategyRegistry = "registry" // ID 列が空なら台帳から採番する
+)
+
+// idRegistry はワークブックごとの ID 台帳（<Book>.ids.json）。
+// テーブル名 → フィールド名 → ID を保持し、行の挿入や並べ替えをしても ID が変わらないようにする。
+// 削除されたフィールドの ID も再利用しないよう台帳に残す。
+type idRegistry struct {
+	path    string
+	changed bool
+	Tables  map[string]map[string]int `json:"tables"`
+}
+
+func registryPath(workbookPath string) string {
+	return strings.TrimSuffix(workbookPath, filepath.Ext(workbookPath)) + ".ids.json"
+}
+
+func loadIDRegistry(workbookPath string) (*idRegistry, error) {
+	reg := &idRegistry{path: registryPath(workbookPath), Tables: map[string]map[string]int{}}
+	b, err := os.ReadFile(reg.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return reg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, reg); err != nil {
+		return nil, fmt.Errorf("%s: %w", reg.path, err)
+	}
+	if reg.Tables == nil {
+		reg.Tables = map[string]map[string]int{}
+	}
+	return reg, nil
+}
+
+func (reg *idRegistry) save() error {
+	if !reg.changed {
+		return nil
+	}
+	b, err := json.MarshalIndent(reg, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(reg.path, append(b, '\n'), 0644)
+}
+
+func (reg *idRegistry) table(name string) map[string]int {
+	t, ok := reg.Tables[name]
+	if !ok {
+		t = map[string]int{}
+		reg.Tables[name] = t
+	}
+	return t
+}
+
+func (reg *idRegistry) set(tableName, fieldName string, id int) {
+	t := reg.table(tableName)
+	if current, ok := t[fieldName]; !ok || current != id {
+		t[fieldName] = id
+		reg.changed = true
+	}
+}
+
+// assignFieldIDs はテーブルの各フィールドに ID を割り当て、ID の重複を検出する。
+func assignFieldIDs(strategy string, reg *idRegistry, tableName string, fields []*field) error {
+	switch strategy {
+	case idStrategyExplicit:
+		next := 0
+		for _, f := range fields {
+			if f.ID == "" {
+				f.ID = f.defaultID
+			}
+			if n, err := strconv.Atoi(f.ID); err == nil {
+				next = max(next, n)
+			}
+		}
+		// 標準フィールドなど行番号のないフィールドは最大値の次から採番する
+		for _, f := range fields {
+			if f.ID == "" {
+				next++
+				f.ID = strconv.Itoa(next)
+			}
+		}
+	case idStrategyRegistry:
+		seenNames := map[string]*field{}
+		for _, f := range fields {
+			if prev, ok := seenNames[f.Name]; ok {
+				return fmt.Errorf("field name %q is defined in %s and %s", f.Name, prev.origin, f.origin)
+			}
+			seenNames[f.Name] = f
+		}
+
+		t := reg.table(tableName)
+		// 明示された ID と台帳の ID を予約済みにしてから、残りを最大値の次から採番する
+		next := 0
+		for _, id := range t {
+			next = max(next, id)
+		}
+		for _, f := range fields {
+			if n, err := strconv.Atoi(f.ID); err == nil {
+				next = max(next, n)
+			}
+		}
+		for _, f := range fields {
+			if f.ID != "" {
+				if n, err := strconv.Atoi(f.ID); err == nil {
+					reg.set(tableName, f.Name, n)
+				}
+				continue
+			}
+			id, ok := t[f.Name]
+			if !ok {
+				next++
+				id = next
+				reg.set(tableName, f.Name, id)
+			}
+			f.ID = strconv.Itoa(id)
+		}
+	default:
+		return fmt.Errorf("unknown ID strategy %q", strategy)
+	}
+
+	seenIDs := map[string]*field{}
+	for _, f := range fields {
+		if prev, ok := seenIDs[f.ID]; ok {
+			return fmt.Errorf("field ID %s is used in %s and %s", f.ID, prev.origin, f.origin)
+		}
+		seenIDs[f.ID] = f
+	}
+	return nil
+}
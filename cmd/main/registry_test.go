@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignFieldIDs(t *testing.T) {
+	type fieldSpec struct {
+		name, id, defaultID string
+	}
+	tests := []struct {
+		name     string
+		strategy string
+		registry map[string]int
+		fields   []fieldSpec
+		want     []string
+		saved    map[string]int
+		err      string
+	}{
+		{
+			name:     "explicit uses the row number",
+			strategy: idStrategyExplicit,
+			fields:   []fieldSpec{{"a", "", "10"}, {"b", "5", "11"}, {"c", "", "12"}},
+			want:     []string{"10", "5", "12"},
+		},
+		{
+			name:     "explicit numbers fields without a row after the maximum",
+			strategy: idStrategyExplicit,
+			fields:   []fieldSpec{{"uuid", "", ""}, {"a", "", "10"}, {"b", "", "11"}},
+			want:     []string{"12", "10", "11"},
+		},
+		{
+			name:     "explicit duplicate",
+			strategy: idStrategyExplicit,
+			fields:   []fieldSpec{{"a", "3", "10"}, {"b", "", "3"}},
+			err:      "field ID 3 is used in a and b",
+		},
+		{
+			name:     "registry keeps registered IDs",
+			strategy: idStrategyRegistry,
+			registry: map[string]int{"a": 1, "b": 2, "deleted": 3},
+			fields:   []fieldSpec{{"b", "", "10"}, {"new", "", "11"}, {"a", "", "12"}},
+			want:     []string{"2", "4", "1"},
+			saved:    map[string]int{"a": 1, "b": 2, "deleted": 3, "new": 4},
+		},
+		{
+			name:     "registry records explicit IDs",
+			strategy: idStrategyRegistry,
+			registry: map[string]int{"a": 1},
+			fields:   []fieldSpec{{"a", "7", "10"}, {"b", "", "11"}},
+			want:     []string{"7", "8"},
+			saved:    map[string]int{"a": 7, "b": 8},
+		},
+		{
+			name:     "registry duplicate name",
+			strategy: idStrategyRegistry,
+			fields:   []fieldSpec{{"a", "", "10"}, {"a", "", "11"}},
+			err:      `field name "a" is defined in a and a`,
+		},
+		{
+			name:     "unknown strategy",
+			strategy: "random",
+			err:      `unknown ID strategy "random"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := &idRegistry{Tables: map[string]map[string]int{}}
+			if tt.registry != nil {
+				reg.Tables["t"] = tt.registry
+			}
+			var fields []*field
+			for _, spec := range tt.fields {
+				fields = append(fields, &field{Name: spec.name, ID: spec.id, defaultID: spec.defaultID, origin: spec.name})
+			}
+			err := assignFieldIDs(tt.strategy, reg, "t", fields)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, f := range fields {
+				got = append(got, f.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IDs = %q, want %q", got, tt.want)
+			}
+			if tt.saved != nil && !reflect.DeepEqual(reg.Tables["t"], tt.saved) {
+				t.Errorf("registry = %v, want %v", reg.Tables["t"], tt.saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// schema はテーブル定義の中間表現。ワークブックや YAML/JSON から読み込み、fmxmlsnippet などの出力に変換する。
+// 値は FileMaker の XML と同じ語彙（Normal, Text, True/False など）で持つ。
+type schema struct {
+	Functions []*customFunction `json:"functions,omitempty" yaml:"functions,omitempty"`
+	Standard  *standardFields   `json:"standard,omitempty" yaml:"standard,omitempty"`
+	Tables    []*baseTable      `json:"tables" yaml:"tables"`
+
+	defaults fieldMapping // config.xml の <Defaults> の値（convert で YAML/JSON に書き出すときに省略する値）
+}
+
+type baseTable struct {
+	Name             string       `json:"name" yaml:"name"`
+	NoStandardFields bool         `json:"noStandardFields,omitempty" yaml:"noStandardFields,omitempty"` // 標準フィールドを追加しない
+	Fields           []*field     `json:"fields" yaml:"fields"`
+	Script           []*scriptRow `json:"script,omitempty" yaml:"script,omitempty"` // スクリプトシートの行
+
+	standard []*field // テーブルのシートの既定値で読み込んだ標準フィールド（ワークブックの入力のみ）
+}
+
+type field struct {
+	ID          string        `json:"id,omitempty" yaml:"id,omitempty"`
+	Name        string        `json:"name" yaml:"name"`
+	FieldType   string        `json:"fieldType,omitempty" yaml:"fieldType,omitempty"`
+	DataType    string        `json:"dataType,omitempty" yaml:"dataType,omitempty"`
+	Comment     string        `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Calculation *calculation  `json:"calculation,omitempty" yaml:"calculation,omitempty"`
+	Summary     *summaryInfo  `json:"summary,omitempty" yaml:"summary,omitempty"`
+	AutoEnter   autoEnter     `json:"autoEnter,omitzero" yaml:"autoEnter,omitempty"`
+	Validation  validation    `json:"validation,omitzero" yaml:"validation,omitempty"`
+	Storage     storage       `json:"storage,omitzero" yaml:"storage,omitempty"`
+	Access      []fieldAccess `json:"access,omitempty" yaml:"access,omitempty"`
+
+	origin     string // エラー表示用の読み込み元（"シート!セル" など）
+	nameOrigin string // フィールド名の読み込み元（空のときは origin）
+	defaultID  string // explicit で ID が空のときに使う ID
+}
+
+// nameCell はフィールド名についてのエラー表示用の読み込み元を返す。
+func (f *field) nameCell() string {
+	if f.nameOrigin != "" {
+		return f.nameOrigin
+	}
+	return f.origin
+}
+
+type calculation struct {
+	Table string `json:"table,omitempty" yaml:"table,omitempty"`
+	Text  string `json:"text" yaml:"text"`
+}
+
+type summaryInfo struct {
+	Repetition string `json:"repetition,omitempty" yaml:"repetition,omitempty"` // Together / Individually
+	Operation  string `json:"operation" yaml:"operation"`                       // Total, Average, Count, List ...
+	Field      string `json:"field" yaml:"field"`                               // 集計対象のフィールド名
+
+	origin string
+	target *field // prepareSchema で解決した集計対象
+}
+
+// autoEnterValues は作成・修正情報などの自動入力の種類と、AutoEnter 要素の value 属性の値。
+var autoEnterValues = map[string]string{
+	"作成日":     "CreationDate",
+	"作成時刻":    "CreationTime",
+	"作成TS":    "CreationTimeStamp",
+	"作成者名":    "CreationName",
+	"作成者":     "CreationAccountName",
+	"修正日":     "ModificationDate",
+	"修正時刻":    "ModificationTime",
+	"修正TS":    "ModificationTimeStamp",
+	"修正者名":    "ModificationName",
+	"修正者":     "ModificationAccountName",
+	"直前のレコード": "PreviousRecord",
+}
+
+// autoEnterProhibitSuffixes は自動入力の種類に続けて書くと「値の変更を禁止」になる表記（例: シリアル番号+変更禁止）。
+var autoEnterProhibitSuffixes = []string{"+変更禁止", "＋変更禁止", "・変更禁止", "(変更禁止)", "（変更禁止）"}
+
+func isKnownAutoEnterKind(kind string) bool {
+	switch kind {
+	case "", "固定値", "計算値", "シリアル番号":
+		return true
+	}
+	_, ok := autoEnterValues[kind]
+	return ok
+}
+
+// normalizeKind は種類の「変更禁止」の表記を取り除き、値の変更を禁止にする。種類で分岐する前に呼ぶ。
+func (a *autoEnter) normalizeKind() {
+	for _, suffix := range autoEnterProhibitSuffixes {
+		if kind, ok := strings.CutSuffix(a.Kind, suffix); ok {
+			a.Kind = strings.TrimSpace(kind)
+			a.AllowEditing = "False"
+			return
+		}
+	}
+}
+
+type autoEnter struct {
+	// Kind はワークブックの「自動入力 タイプ」列と同じ語彙（固定値, 計算値, シリアル番号, 作成TS ...）。
+	Kind                   string       `json:"kind,omitempty" yaml:"kind,omitempty"`
+	ConstantData           string       `json:"constantData,omitempty" yaml:"constantData,omitempty"`
+	Calculation            *calculation `json:"calculation,omitempty" yaml:"calculation,omitempty"`
+	Serial                 *serial      `json:"serial,omitempty" yaml:"serial,omitempty"`
+	AlwaysEvaluate         string       `json:"alwaysEvaluate,omitempty" yaml:"alwaysEvaluate,omitempty"`
+	OverwriteExistingValue string       `json:"overwriteExistingValue,omitempty" yaml:"overwriteExistingValue,omitempty"`
+	AllowEditing           string       `json:"allowEditing,omitempty" yaml:"allowEditing,omitempty"`
+	Furigana               string       `json:"furigana,omitempty" yaml:"furigana,omitempty"`
+	Lookup                 string       `json:"lookup,omitempty" yaml:"lookup,omitempty"`
+}
+
+type serial struct {
+	Increment string `json:"increment,omitempty" yaml:"increment,omitempty"`
+	NextValue string `json:"nextValue,omitempty" yaml:"nextValue,omitempty"`
+	Generate  string `json:"generate,omitempty" yaml:"generate,omitempty"`
+}
+
+type validation struct {
+	Type                      string `json:"type,omitempty" yaml:"type,omitempty"`
+	Message                   string `json:"message,omitempty" yaml:"message,omitempty"`
+	Valuelist                 string `json:"valuelist,omitempty" yaml:"valuelist,omitempty"`
+	Calculation               string `json:"calculation,omitempty" yaml:"calculation,omitempty"`
+	AlwaysValidateCalculation string `json:"alwaysValidateCalculation,omitempty" yaml:"alwaysValidateCalculation,omitempty"`
+	StrictDataType            string `json:"strictDataType,omitempty" yaml:"strictDataType,omitempty"`
+	Unique                    string `json:"unique,omitempty" yaml:"unique,omitempty"`
+	NotEmpty                  string `json:"notEmpty,omitempty" yaml:"notEmpty,omitempty"`
+	MaxDataLength             string `json:"maxDataLength,omitempty" yaml:"maxDataLength,omitempty"`
+	Existing                  string `json:"existing,omitempty" yaml:"existing,omitempty"`
+	StrictValidation          string `json:"strictValidation,omitempty" yaml:"strictValidation,omitempty"`
+	// Values は値一覧の値。スニペットには出力されず、JSON Schema の enum などに使う
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+type storage struct {
+	AutoIndex     string `json:"autoIndex,omitempty" yaml:"autoIndex,omitempty"`
+	Index         string `json:"index,omitempty" yaml:"index,omitempty"`
+	IndexLanguage string `json:"indexLanguage,omitempty" yaml:"indexLanguage,omitempty"`
+	Global        string `json:"global,omitempty" yaml:"global,omitempty"`
+	MaxRepetition string `json:"maxRepetition,omitempty" yaml:"maxRepetition,omitempty"`
+}
+
+// defaultValue は空欄のときに使う値。
+type defaultValue struct {
+	value *string
+	def   string
+}
+
+// defaults は config.xml の <Defaults> で指定されなかったプロパティに使う組み込みのデフォルト値の一覧。
+// <Defaults> のない古い config.xml のためのもので、組み込みの config.xml の <Defaults> と同じ値にしておく。
+func (f *field) defaults() []defaultValue {
+	return []defaultValue{
+		{&f.FieldType, "Normal"},
+		{&f.DataType, "Text"},
+		{&f.AutoEnter.AlwaysEvaluate, "False"},
+		{&f.AutoEnter.OverwriteExistingValue, "False"},
+		{&f.AutoEnter.AllowEditing, "True"},
+		{&f.AutoEnter.Furigana, "False"},
+		{&f.AutoEnter.Lookup, "False"},
+		{&f.Validation.Type, "OnlyDuringDataEntry"},
+		{&f.Validation.Message, "False"},
+		{&f.Validation.Valuelist, "False"},
+		{&f.Validation.Calculation, "False"},
+		{&f.Validation.AlwaysValidateCalculation, "False"},
+		{&f.Validation.Unique, "False"},
+		{&f.Validation.NotEmpty, "False"},
+		{&f.Validation.Existing, "False"},
+		{&f.Storage.AutoIndex, "True"},
+		{&f.Storage.Index, "None"},
+		{&f.Storage.IndexLanguage, "Japanese"},
+		{&f.Storage.Global, "False"},
+		{&f.Storage.MaxRepetition, "1"},
+	}
+}
+
+func (f *field) setDefaults() {
+	f.AutoEnter.normalizeKind()
+	if f.FieldType == "Summary" {
+		f.DataType = "Number"
+	}
+	for _, d := range f.defaults() {
+		if *d.value == "" {
+			*d.value = d.def
+		}
+	}
+	if f.Summary != nil && f.Summary.Repetition == "" {
+		f.Summary.Repetition = "Together"
+	}
+}
+
+// setConfigDefaults は YAML/JSON のフィールドの空のプロパティに config.xml の <Defaults> の値（def）を入れ、
+// 残りを setDefaults で補う。
+func (f *field) setConfigDefaults(def fieldMapping) {
+	for _, d := range f.configDefaults(&def) {
+		if *d.value == "" {
+			*d.value = *d.def
+		}
+	}
+	f.setDefaults()
+}
+
+// clearDefaults はデフォルトと同じ値を空にする（convert の出力を読みやすくするため）。
+// デフォルトは config.xml の <Defaults> の値（def）、指定がなければ組み込みの値で、setConfigDefaults で読み戻せる。
+func (f *field) clearDefaults(def fieldMapping) {
+	defaults := map[*string]string{}
+	for _, d := range f.configDefaults(&def) {
+		if *d.def != "" {
+			defaults[d.value] = *d.def
+		}
+	}
+	for _, d := range f.defaults() {
+		if _, ok := defaults[d.value]; !ok {
+			defaults[d.value] = d.def
+		}
+	}
+	for value, def := range defaults {
+		if *value == def {
+			*value = ""
+		}
+	}
+	if f.FieldType == "Summary" {
+		f.DataType = ""
+	}
+}
+
+// prepareSchema は ID の割り当てと集計対象フィールドの解決を行う。
+// 集計対象の従来の "id.フィールド名" の ID が実際の ID と違う場合は警告として返す。
+func prepareSchema(s *schema, strategy string, reg *idRegistry) ([]string, error) {
+	var warnings []string
+	if err := validateFunctions(s.Functions); err != nil {
+		return nil, err
+	}
+	if err := injectStandardFields(s); err != nil {
+		return nil, err
+	}
+	for _, t := range s.Tables {
+		if err := assignFieldIDs(strategy, reg, t.Name, t.Fields); err != nil {
+			return nil, err
+		}
+		for _, f := range t.Fields {
+			if err := normalizeAccess(f); err != nil {
+				return nil, err
+			}
+			if f.FieldType != "Summary" {
+				continue
+			}
+			if f.Summary == nil {
+				return nil, fmt.Errorf("%s: summary field %q has no summary definition", f.origin, f.Name)
+			}
+			target, staleID, err := resolveSummaryField(f.Summary.Field, t.Name, t.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Summary.origin, err)
+			}
+			if staleID != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: summary field %q: ID %s is replaced with %s (the field with that name)", f.Summary.origin, f.Summary.Field, staleID, target.ID))
+			}
+			f.Summary.target = target
+		}
+		if err := resolveScriptRows(t); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// resolveSummaryField は集計対象フィールドの参照を同じテーブルのフィールドから解決する。
+// "フィールド名"・"テーブル::フィールド名"・従来の "id.フィールド名" を受け付ける。
+// 従来の形式の ID が解決したフィールドの ID と違う場合は、その ID を staleID に返す。
+func resolveSummaryField(ref, tableName string, fields []*field) (target *field, staleID string, err error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, "", errors.New("summary field reference is empty")
+	}
+	if table, name, ok := strings.Cut(ref, "::"); ok {
+		if table != tableName {
+			return nil, "", fmt.Errorf("summary field %q must be in table %q", ref, tableName)
+		}
+		ref = name
+	}
+
+	find := func(name string) ([]*field, error) {
+		var matches []*field
+		for _, f := range fields {
+			if f.Name == name {
+				matches = append(matches, f)
+			}
+		}
+		if len(matches) > 1 {
+			return nil, fmt.Errorf("summary field %q is ambiguous: defined in %s and %s", name, matches[0].origin, matches[1].origin)
+		}
+		return matches, nil
+	}
+
+	// フィールド名そのものにドットが含まれる場合があるので、名前の完全一致を先に調べる
+	matches, err := find(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(matches) == 1 {
+		return matches[0], "", nil
+	}
+	if id, name, ok := strings.Cut(ref, "."); ok {
+		matches, err = find(name)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(matches) == 1 {
+			if matches[0].ID != id {
+				return matches[0], id, nil
+			}
+			return matches[0], "", nil
+		}
+	}
+	return nil, "", fmt.Errorf("summary field %q is not defined in table %q", ref, tableName)
+}
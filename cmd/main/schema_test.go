@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestResolveSummaryField(t *testing.T) {
+	fields := []*field{
+		{ID: "1", Name: "amount", origin: "t!A10"},
+		{ID: "2", Name: "a.b", origin: "t!A11"},
+		{ID: "3", Name: "b", origin: "t!A12"},
+		{ID: "4", Name: "dup", origin: "t!A13"},
+		{ID: "5", Name: "dup", origin: "t!A14"},
+	}
+	tests := []struct {
+		name  string
+		ref   string
+		want  string // 解決したフィールドの ID
+		stale string // 置き換えられた従来の形式の ID
+		err   string
+	}{
+		{name: "name", ref: "amount", want: "1"},
+		{name: "surrounding spaces", ref: " amount ", want: "1"},
+		{name: "qualified", ref: "t::amount", want: "1"},
+		{name: "name with a dot", ref: "a.b", want: "2"},
+		{name: "legacy id.name", ref: "3.b", want: "3"},
+		{name: "legacy id.name with an old ID", ref: "9.amount", want: "1", stale: "9"},
+		{name: "other table", ref: "u::amount", err: `summary field "u::amount" must be in table "t"`},
+		{name: "ambiguous", ref: "dup", err: `summary field "dup" is ambiguous: defined in t!A13 and t!A14`},
+		{name: "not defined", ref: "nope", err: `summary field "nope" is not defined in table "t"`},
+		{name: "empty", ref: "", err: "summary field reference is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stale, err := resolveSummaryField(tt.ref, "t", fields)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err == nil && f.ID != tt.want {
+				t.Errorf("resolved field %s (%s), want ID %s", f.ID, f.Name, tt.want)
+			}
+			if stale != tt.stale {
+				t.Errorf("stale ID = %q, want %q", stale, tt.stale)
+			}
+		})
+	}
+}
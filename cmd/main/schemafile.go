@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isSchemaFile は入力が YAML/JSON のテーブル定義かどうかを拡張子で判定する。
+func isSchemaFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// readSchemaFile は YAML/JSON のテーブル定義を読み込む。JSON は YAML として読める。
+// 未知のキーは書き間違いとしてエラーにする。空のプロパティには config.xml の <Defaults> の値（def）を使う。
+func readSchemaFile(path string, tables sheetFilter, def fieldMapping) (*schema, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s schema
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err = dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, fn := range s.Functions {
+		if fn == nil {
+			return nil, fmt.Errorf("%s: functions[%d] is empty", path, i)
+		}
+		fn.origin = fmt.Sprintf("%s: functions[%d]", path, i)
+	}
+
+	if s.Standard != nil {
+		for j, f := range s.Standard.Fields {
+			if f == nil {
+				return nil, fmt.Errorf("%s: standard.fields[%d] is empty", path, j)
+			}
+			f.origin = fmt.Sprintf("%s: standard.fields[%d]", path, j)
+			if f.Summary != nil {
+				f.Summary.origin = f.origin + ".summary"
+			}
+			f.setConfigDefaults(def)
+		}
+	}
+
+	var selected []*baseTable
+	for i, t := range s.Tables {
+		if t == nil || !tables.selects(t.Name) {
+			continue
+		}
+		for j, f := range t.Fields {
+			if f == nil {
+				return nil, fmt.Errorf("%s: tables[%d].fields[%d] is empty", path, i, j)
+			}
+			f.origin = fmt.Sprintf("%s: %s.fields[%d]", path, t.Name, j)
+			f.defaultID = strconv.Itoa(j + 1)
+			if f.Summary != nil {
+				f.Summary.origin = f.origin + ".summary"
+			}
+			if f.Name == "" {
+				f.Name = fmt.Sprintf("Field#%d", j+1)
+			}
+			f.setConfigDefaults(def)
+		}
+		for j, row := range t.Script {
+			if row == nil {
+				return nil, fmt.Errorf("%s: tables[%d].script[%d] is empty", path, i, j)
+			}
+			row.origin = fmt.Sprintf("%s: %s.script[%d]", path, t.Name, j)
+		}
+		selected = append(selected, t)
+	}
+	s.Tables = selected
+	return &s, nil
+}
+
+// writeSchemaFile は schema を YAML または JSON（拡張子で判定）で書き出す。デフォルト値（config.xml の <Defaults> の値）と同じ項目は省略する。
+func writeSchemaFile(path string, s *schema) error {
+	for _, t := range s.Tables {
+		for _, f := range t.Fields {
+			if f.Summary != nil && f.Summary.target != nil {
+				f.Summary.Field = f.Summary.target.Name
+			}
+			f.clearDefaults(s.defaults)
+		}
+	}
+
+	var b []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if b, err = json.MarshalIndent(s, "", "  "); err == nil {
+			b = append(b, '\n')
+		}
+	} else {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err = enc.Encode(s); err == nil {
+			err = enc.Close()
+		}
+		b = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// runConvert は convert サブコマンド。ワークブックを config.xml で読み込み、YAML/JSON のテーブル定義に変換する。
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	configFlag := fs.String("config", "", "config.xml to use (found as for generation when omitted)")
+	output := fs.String("o", "", "output file (.yaml, .yml or .json); defaults to <workbook>.yaml")
+	idStrategy := fs.String("ids", idStrategyExplicit, "field ID strategy: explicit or registry")
+	var include, exclude stringList
+	fs.Var(&include, "sheet", "convert only sheets matching this glob or /regexp/ (repeatable)")
+	fs.Var(&exclude, "exclude", "skip sheets matching this glob or /regexp/ (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: generateTables convert [-o schema.yaml] /path/to/Book.xlsx")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return withExitCode(exitUsage, fmt.Errorf("convert: expected 1 input, got %d", fs.NArg()))
+	}
+
+	configPath, err := findConfig(*configFlag, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	opts := options{
+		configPath:   configPath,
+		workbookPath: fs.Arg(0),
+		idStrategy:   *idStrategy,
+		sheets:       sheetFilter{include: include, exclude: exclude},
+	}
+	if err := opts.sheets.validate(); err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	s, err := loadSchema(opts)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = outputPath(opts.workbookPath, ".yaml")
+	}
+	if err = writeSchemaFile(*output, s); err != nil {
+		return err
+	}
+	fmt.Println("wrote", *output)
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSchemaFileNamingRules(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "book.yaml")
+	if err := os.WriteFile(schemaPath, []byte("tables:\n  - name: t\n    fields:\n      - name: memo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.xml")
+	config := strings.Replace(string(defaultConfig), "<BaseTable", `<NamingRules severity="error"><Reserved>memo</Reserved></NamingRules>
+	<BaseTable`, 1)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := options{workbookPath: schemaPath, idStrategy: idStrategyExplicit}
+	if _, err := loadSchema(opts); err != nil {
+		t.Fatalf("without config.xml: %v", err)
+	}
+	opts.configPath = configPath
+	_, err := loadSchema(opts)
+	want := `book.yaml: t.fields[0]: field "memo": name is a reserved word or function name`
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+	if code := exitCode(err); code != exitLint {
+		t.Errorf("exit code = %d, want %d", code, exitLint)
+	}
+}
+
+func TestReadSchemaFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		fields []string // "名前 fieldType dataType"
+		err    string
+	}{
+		{
+			name:   "defaults and unnamed fields",
+			data:   "tables:\n  - name: t\n    fields:\n      - name: id\n        dataType: Number\n      - fieldType: Summary\n        summary: {operation: Count, field: id}\n",
+			fields: []string{"id Normal Number", "Field#2 Summary Number"},
+		},
+		{
+			name:   "json",
+			data:   `{"tables": [{"name": "t", "fields": [{"name": "memo"}]}]}`,
+			fields: []string{"memo Normal Text"},
+		},
+		{
+			name: "unknown key",
+			data: "tables:\n  - name: t\n    fields:\n      - name: id\n        datatype: Number\n",
+			err:  "field datatype not found in type main.field",
+		},
+		{
+			name: "empty field",
+			data: "tables:\n  - name: t\n    fields:\n      -\n",
+			err:  "tables[0].fields[0] is empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "book.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := readSchemaFile(path, sheetFilter{}, fieldMapping{})
+			if (err == nil) != (tt.err == "") || err != nil && !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, f := range s.Tables[0].Fields {
+				got = append(got, strings.Join([]string{f.Name, f.FieldType, f.DataType}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %q, want %q", got, tt.fields)
+			}
+		})
+	}
+}
+
+// convert で書き出した YAML/JSON を読み戻すと、同じ fmxmlsnippet になる。
+func TestWriteSchemaFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "book.yaml")
+	data := `tables:
+  - name: t
+    fields:
+      - name: id
+        dataType: Number
+        autoEnter: {kind: シリアル番号, serial: {nextValue: "1", increment: "1", generate: OnCreation}}
+        validation: {unique: "True", notEmpty: "True"}
+      - name: price
+        dataType: Number
+        comment: 税抜
+      - name: total
+        fieldType: Calculated
+        dataType: Number
+        calculation: {text: price * 1.1}
+      - name: count
+        fieldType: Summary
+        summary: {operation: Count, field: id}
+`
+	if err := os.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	load := func(path string) string {
+		t.Helper()
+		s, err := readSchemaFile(path, sheetFilter{}, fieldMapping{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := prepareSchema(s, idStrategyExplicit, nil); err != nil {
+			t.Fatal(err)
+		}
+		snippet := renderSnippet(s).OutputXML(true)
+		for _, ext := range []string{".yaml", ".json"} {
+			if err := writeSchemaFile(filepath.Join(dir, "out"+ext), s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return snippet
+	}
+
+	want := load(input)
+	for _, ext := range []string{".yaml", ".json"} {
+		if got := load(filepath.Join(dir, "out"+ext)); got != want {
+			t.Errorf("%s round trip:\n%s\nwant\n%s", ext, got, want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	scriptStepReplace = "replace"
+	scriptStepSet     = "set"
+)
+
+// scriptRow はスクリプトシートの 1 行。対象フィールドに計算式の結果を入れる。
+type scriptRow struct {
+	Field       string `json:"field" yaml:"field"`
+	Calculation string `json:"calculation" yaml:"calculation"`
+
+	table  string // スクリプトシートに書かれたテーブル名（ワークブックの入力のみ）
+	origin string
+	target *field
+}
+
+// scriptColumns は config.xml の <ScriptSteps>。スクリプトシートのシート名と、最初の行の各列のセル参照。
+type scriptColumns struct {
+	Sheet       string `xml:"sheet,attr"`
+	Table       string `xml:"table,attr" config:"cell"`
+	Field       string `xml:"field,attr" config:"cell"`
+	Calculation string `xml:"calculation,attr" config:"cell"`
+}
+
+var defaultScriptColumns = scriptColumns{Sheet: "#SCRIPT", Table: "A2", Field: "B2", Calculation: "C2"}
+
+// scriptColumns は config.xml の <ScriptSteps> を返す。省略した属性はデフォルトの列を使う。
+func (rec fmxmlSnippet) scriptColumns() scriptColumns {
+	if rec.ScriptSteps == nil {
+		return defaultScriptColumns
+	}
+	c := *rec.ScriptSteps
+	for _, d := range []defaultValue{
+		{&c.Sheet, defaultScriptColumns.Sheet},
+		{&c.Table, defaultScriptColumns.Table},
+		{&c.Field, defaultScriptColumns.Field},
+		{&c.Calculation, defaultScriptColumns.Calculation},
+	} {
+		if *d.value == "" {
+			*d.value = d.def
+		}
+	}
+	return c
+}
+
+// parseScriptRows はスクリプトシート（デフォルトは #SCRIPT）を読み込み、テーブル名の一致する BaseTable に追加する。
+// シートがなければ何もしない。-sheet / -exclude で読み込まなかったテーブルの行は無視する。
+func parseScriptRows(book sheetReader, rec fmxmlSnippet, tables []*baseTable, filtered bool) error {
+	cols := rec.scriptColumns()
+	if !slices.Contains(book.GetSheetList(), cols.Sheet) {
+		return nil
+	}
+	_, start, err := excelize.SplitCellName(cols.Field)
+	if err != nil {
+		return fmt.Errorf("ScriptSteps field: %w", err)
+	}
+	rows, err := book.GetRows(cols.Sheet)
+	if err != nil {
+		return err
+	}
+
+	for rowIndex := start - 1; rowIndex < len(rows); rowIndex++ {
+		cell := func(cellName string) string {
+			value, _ := book.GetCellValue(cols.Sheet, rowCell(cellName, rowIndex))
+			return strings.TrimSpace(value)
+		}
+		row := &scriptRow{
+			Field:       cell(cols.Field),
+			Calculation: cell(cols.Calculation),
+			table:       cell(cols.Table),
+			origin:      cellOrigin(cols.Sheet, cols.Field, rowIndex),
+		}
+		if row.Field == "" && row.Calculation == "" {
+			continue
+		}
+		i := slices.IndexFunc(tables, func(t *baseTable) bool { return strings.EqualFold(t.Name, row.table) })
+		if i < 0 {
+			if filtered {
+				continue
+			}
+			return fmt.Errorf("%s: table %q is not defined", row.origin, row.table)
+		}
+		tables[i].Script = append(tables[i].Script, row)
+	}
+	return nil
+}
+
+// resolveScriptRows はスクリプトシートの行の対象フィールドを解決する。FileMaker と同じく大文字小文字は区別しない。
+// フィールド設定や全置換で値を入れられない計算・集計フィールドはエラーにする。
+func resolveScriptRows(t *baseTable) error {
+	for _, row := range t.Script {
+		if row.Field == "" {
+			return fmt.Errorf("%s: script target field is empty", row.origin)
+		}
+		if row.Calculation == "" {
+			return fmt.Errorf("%s: script calculation for %q is empty", row.origin, row.Field)
+		}
+		i := slices.IndexFunc(t.Fields, func(f *field) bool { return strings.EqualFold(f.Name, row.Field) })
+		if i < 0 {
+			return fmt.Errorf("%s: field %q is not defined in table %q", row.origin, row.Field, t.Name)
+		}
+		if f := t.Fields[i]; f.FieldType != "Normal" {
+			return fmt.Errorf("%s: field %q is a %s field and cannot be set by a script", row.origin, row.Field, strings.ToLower(f.FieldType))
+		}
+		row.target = t.Fields[i]
+	}
+	return nil
+}
+
+// FileMaker のスクリプトステップ ID
+var scriptStepIDs = map[string]string{
+	"Go to Layout":            "6",
+	"New Record/Request":      "7",
+	"Show All Records":        "23",
+	"Commit Records/Requests": "75",
+	"Set Field":               "76",
+	"Comment":                 "89",
+	"Replace Field Contents":  "91",
+}
+
+func newStep(name string, children ...*xmlquery.Node) *xmlquery.Node {
+	stepElement := &xmlquery.Node{
+		Data: "Step",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{
+			{Name: xml.Name{Local: "enable"}, Value: "True"},
+			{Name: xml.Name{Local: "id"}, Value: scriptStepIDs[name]},
+			{Name: xml.Name{Local: "name"}, Value: name},
+		},
+	}
+	for _, child := range children {
+		xmlquery.AddChild(stepElement, child)
+	}
+	return stepElement
+}
+
+var calcStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "¶", `\¶`)
+
+// constantCalculation は固定値を dataType に合わせた計算式にする。
+func constantCalculation(dataType, value string) string {
+	literal := `"` + calcStringReplacer.Replace(value) + `"`
+	switch dataType {
+	case "Number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+		return "GetAsNumber(" + literal + ")"
+	case "Date":
+		return "GetAsDate(" + literal + ")"
+	case "Time":
+		return "GetAsTime(" + literal + ")"
+	case "TimeStamp":
+		return "GetAsTimestamp(" + literal + ")"
+	}
+	return literal
+}
+
+// scriptTarget は出力するステップの対象フィールドと計算式。
+type scriptTarget struct {
+	field       *field
+	calculation string
+}
+
+// scriptTargets はテーブルのステップの対象を返す。自動入力の固定値のフィールドの後にスクリプトシートの行を並べる。
+// 同じフィールドがスクリプトシートにもあれば、固定値ではなくスクリプトシートの計算式を使う。
+func scriptTargets(t *baseTable) []scriptTarget {
+	var targets []scriptTarget
+	for _, f := range t.Fields {
+		if f.FieldType != "Normal" || f.AutoEnter.Kind != "固定値" || f.AutoEnter.ConstantData == "" {
+			continue
+		}
+		if slices.ContainsFunc(t.Script, func(row *scriptRow) bool { return row.target == f }) {
+			continue
+		}
+		targets = append(targets, scriptTarget{f, constantCalculation(f.DataType, f.AutoEnter.ConstantData)})
+	}
+	for _, row := range t.Script {
+		targets = append(targets, scriptTarget{row.target, row.Calculation})
+	}
+	return targets
+}
+
+// renderScript は自動入力の固定値とスクリプトシートの計算式をデータに入れるスクリプトステップの fmxmlsnippet を返す。
+// replace は全レコードに「フィールド内容の全置換」、set は新規レコードに「フィールド設定」を行う。
+// レイアウトはテーブルと同じ名前のもの（テーブル作成時に自動で作られるレイアウト）を使う。
+func renderScript(s *schema, opts outputOptions) ([]byte, error) {
+	if opts.scriptStep != scriptStepReplace && opts.scriptStep != scriptStepSet {
+		return nil, fmt.Errorf("unknown script step %q (%s, %s)", opts.scriptStep, scriptStepReplace, scriptStepSet)
+	}
+
+	rootElement := newElement("fmxmlsnippet", "type", "FMObjectList")
+	for _, t := range s.Tables {
+		targets := scriptTargets(t)
+		if len(targets) == 0 {
+			continue
+		}
+
+		xmlquery.AddChild(rootElement, newStep("Comment", newTextElement("Text", t.Name)))
+		xmlquery.AddChild(rootElement, newStep("Go to Layout",
+			newElement("LayoutDestination", "value", "SelectedLayout"),
+			newElement("Layout", "id", "0", "name", t.Name)))
+		if opts.scriptStep == scriptStepSet {
+			xmlquery.AddChild(rootElement, newStep("New Record/Request"))
+		} else {
+			xmlquery.AddChild(rootElement, newStep("Show All Records"))
+		}
+		for _, target := range targets {
+			calcElement := newTextElement("Calculation", target.calculation)
+			fieldElement := newElement("Field", "table", t.Name, "id", target.field.ID, "name", target.field.Name)
+			if opts.scriptStep == scriptStepSet {
+				xmlquery.AddChild(rootElement, newStep("Set Field", calcElement, fieldElement))
+			} else {
+				xmlquery.AddChild(rootElement, newStep("Replace Field Contents",
+					newElement("NoInteract", "state", "True"),
+					newElement("With", "value", "Calculation"),
+					calcElement, fieldElement))
+			}
+		}
+		xmlquery.AddChild(rootElement, newStep("Commit Records/Requests",
+			newElement("NoInteract", "state", "True"),
+			newElement("Option", "state", "False"),
+			newElement("ESSForceCommit", "state", "False")))
+	}
+	if rootElement.FirstChild == nil {
+		return nil, errors.New("no fields with a constant auto-enter value or a script sheet row")
+	}
+	return []byte(rootElement.OutputXML(true)), nil
+}
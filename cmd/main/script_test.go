@@ -0,0 +1,145 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestParseScriptRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		filtered bool
+		want     []string // "テーブル: フィールド = 計算式"
+		err      string
+	}{
+		{
+			name: "rows are added to the table",
+			rows: [][]string{{"table", "field", "calculation"}, {"t", "status", `"new"`}, {}, {"T", "date", "Get ( CurrentDate )"}},
+			want: []string{`t: status = "new"`, "t: date = Get ( CurrentDate )"},
+		},
+		{
+			name: "unknown table",
+			rows: [][]string{{"table", "field", "calculation"}, {"u", "status", `"new"`}},
+			err:  `#SCRIPT!B2: table "u" is not defined`,
+		},
+		{
+			name:     "unknown table skipped with -sheet",
+			rows:     [][]string{{"table", "field", "calculation"}, {"u", "status", `"new"`}},
+			filtered: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newGridReader()
+			book.add("#SCRIPT", tt.rows)
+			tables := []*baseTable{{Name: "t"}}
+			err := parseScriptRows(book, fmxmlSnippet{}, tables, tt.filtered)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			var got []string
+			for _, row := range tables[0].Script {
+				got = append(got, tables[0].Name+": "+row.Field+" = "+row.Calculation)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveScriptRows(t *testing.T) {
+	fields := []*field{
+		{ID: "1", Name: "status", FieldType: "Normal"},
+		{ID: "2", Name: "total", FieldType: "Calculated"},
+	}
+	tests := []struct {
+		name string
+		row  scriptRow
+		want string // 解決したフィールドの ID
+		err  string
+	}{
+		{name: "case-insensitive", row: scriptRow{Field: "Status", Calculation: `"new"`}, want: "1"},
+		{name: "not defined", row: scriptRow{Field: "nope", Calculation: "1"}, err: `#SCRIPT!B2: field "nope" is not defined in table "t"`},
+		{name: "calculated", row: scriptRow{Field: "total", Calculation: "1"}, err: `#SCRIPT!B2: field "total" is a calculated field and cannot be set by a script`},
+		{name: "empty calculation", row: scriptRow{Field: "status"}, err: `#SCRIPT!B2: script calculation for "status" is empty`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			row.origin = "#SCRIPT!B2"
+			err := resolveScriptRows(&baseTable{Name: "t", Fields: fields, Script: []*scriptRow{&row}})
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err == nil && row.target.ID != tt.want {
+				t.Errorf("target ID = %s, want %s", row.target.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderScript(t *testing.T) {
+	status := &field{ID: "1", Name: "status", FieldType: "Normal", DataType: "Text", AutoEnter: autoEnter{Kind: "固定値", ConstantData: "new"}}
+	count := &field{ID: "2", Name: "count", FieldType: "Normal", DataType: "Number", AutoEnter: autoEnter{Kind: "固定値", ConstantData: "0"}}
+	date := &field{ID: "3", Name: "date", FieldType: "Normal", DataType: "Date"}
+	tests := []struct {
+		name   string
+		script []*scriptRow
+		step   string
+		want   []string // "ステップ名: フィールド = 計算式"
+		err    string
+	}{
+		{
+			name: "constant values",
+			step: scriptStepReplace,
+			want: []string{"Comment", "Go to Layout", "Show All Records", `Replace Field Contents: status = "new"`, "Replace Field Contents: count = 0", "Commit Records/Requests"},
+		},
+		{
+			name:   "script sheet rows follow and override constant values",
+			script: []*scriptRow{{Field: "date", Calculation: "Get ( CurrentDate )", target: date}, {Field: "status", Calculation: `"draft"`, target: status}},
+			step:   scriptStepSet,
+			want:   []string{"Comment", "Go to Layout", "New Record/Request", "Set Field: count = 0", "Set Field: date = Get ( CurrentDate )", `Set Field: status = "draft"`, "Commit Records/Requests"},
+		},
+		{
+			name: "unknown step",
+			step: "insert",
+			err:  `unknown script step "insert" (replace, set)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{status, count, date}, Script: tt.script}}}
+			out, err := renderScript(s, outputOptions{scriptStep: tt.step})
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			doc, err := xmlquery.Parse(strings.NewReader(string(out)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, step := range xmlquery.Find(doc, "/fmxmlsnippet/Step") {
+				name := step.SelectAttr("name")
+				if fieldElement := step.SelectElement("Field"); fieldElement != nil {
+					name += ": " + fieldElement.SelectAttr("name") + " = " + step.SelectElement("Calculation").InnerText()
+				}
+				got = append(got, name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("steps = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := renderScript(&schema{Tables: []*baseTable{{Name: "t", Fields: []*field{date}}}}, outputOptions{scriptStep: scriptStepSet}); err == nil {
+		t.Error("renderScript without targets succeeded")
+	}
+}
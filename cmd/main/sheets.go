@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// stringList は繰り返し指定できるフラグの値。
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// matchPattern はシート名がパターンに一致するかを返す。
+// "/.../" で囲んだパターンは正規表現、それ以外は glob（*, ?, [...]）として扱う。
+func matchPattern(pattern, name string) (bool, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(name), nil
+	}
+	return path.Match(pattern, name)
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := matchPattern(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid sheet pattern %q: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sheetFilter は -sheet / -exclude で指定されたシートの絞り込み。
+type sheetFilter struct {
+	include []string
+	exclude []string
+}
+
+// validate はすべてのパターンを事前に評価し、書式の誤りを検出する。
+func (f sheetFilter) validate() error {
+	if _, err := matchAny(f.include, ""); err != nil {
+		return err
+	}
+	_, err := matchAny(f.exclude, "")
+	return err
+}
+
+func (f sheetFilter) selects(sheetName string) bool {
+	if len(f.include) > 0 {
+		if ok, _ := matchAny(f.include, sheetName); !ok {
+			return false
+		}
+	}
+	ok, _ := matchAny(f.exclude, sheetName)
+	return !ok
+}
+
+// patternElement は config.xml の <Include pattern="..."/> / <Exclude pattern="..."/>。
+type patternElement struct {
+	Pattern string `xml:"pattern,attr"`
+}
+
+// sheetRules は config.xml の <Sheets>。省略時は名前に # を含むシートを除外する。
+type sheetRules struct {
+	Include []patternElement `xml:"Include"`
+	Exclude []patternElement `xml:"Exclude"`
+}
+
+var defaultSheetRules = sheetRules{Exclude: []patternElement{{Pattern: "*#*"}}}
+
+func (rules sheetRules) filter() sheetFilter {
+	var f sheetFilter
+	for _, e := range rules.Include {
+		f.include = append(f.include, e.Pattern)
+	}
+	for _, e := range rules.Exclude {
+		f.exclude = append(f.exclude, e.Pattern)
+	}
+	return f
+}
+
+// rowRules は config.xml の <Rows>。データ行の範囲と有効/無効の判定を指定する。
+type rowRules struct {
+	Start      string `xml:"start,attr"`                // データ開始行（省略時は Field id のセル参照の行）
+	End        string `xml:"end,attr"`                  // データ最終行
+	Terminator string `xml:"terminator,attr"`           // この値のセルがある行の手前で終了する（例: END）
+	Active     string `xml:"active,attr" config:"cell"` // 行の有効/無効を表す列のセル参照
+	MinColumns string `xml:"minColumns,attr"`           // 値が入っている最後の列がこれより前の行は空行とみなす
+}
+
+// dataRange は rowRules を解釈した結果。行・列番号は 1 始まり、0 は指定なし。
+type dataRange struct {
+	start        int
+	end          int
+	terminator   string
+	activeColumn int
+	minColumns   int
+}
+
+func (rec fmxmlSnippet) dataRange() (dataRange, error) {
+	rules := rec.Rows
+	dr := dataRange{terminator: strings.TrimSpace(rules.Terminator), minColumns: 2}
+
+	var err error
+	if rules.Start != "" {
+		if dr.start, err = strconv.Atoi(rules.Start); err != nil {
+			return dr, fmt.Errorf("Rows start: %w", err)
+		}
+	} else if _, dr.start, err = excelize.SplitCellName(rec.BaseTable.Field.ID); err != nil {
+		return dr, err
+	}
+	if rules.End != "" {
+		if dr.end, err = strconv.Atoi(rules.End); err != nil {
+			return dr, fmt.Errorf("Rows end: %w", err)
+		}
+	}
+	if rules.Active != "" {
+		if dr.activeColumn, _, err = excelize.CellNameToCoordinates(rules.Active); err != nil {
+			return dr, fmt.Errorf("Rows active: %w", err)
+		}
+	}
+	if rules.MinColumns != "" {
+		if dr.minColumns, err = strconv.Atoi(rules.MinColumns); err != nil {
+			return dr, fmt.Errorf("Rows minColumns: %w", err)
+		}
+	}
+	return dr, nil
+}
+
+// sheetFilter は config.xml の <Sheets> の規則を返す。
+func (rec fmxmlSnippet) sheetFilter() sheetFilter {
+	if rec.Sheets == nil {
+		return defaultSheetRules.filter()
+	}
+	return rec.Sheets.filter()
+}
+
+// inactiveValues は有効列に入っていると行を無視する値。
+var inactiveValues = []string{"false", "0", "-", "×", "無効"}
+
+// dataRowIndexes はデータ範囲内で値が入っている有効な行のインデックスを返す。
+func dataRowIndexes(rows [][]string, dr dataRange) []int {
+	var rowIndexes []int
+	for rowIndex, row := range rows {
+		if rowIndex < dr.start-1 {
+			continue
+		}
+		if dr.end > 0 && rowIndex >= dr.end {
+			break
+		}
+		if dr.terminator != "" && slices.ContainsFunc(row, func(v string) bool { return strings.TrimSpace(v) == dr.terminator }) {
+			break
+		}
+		if len(row) < dr.minColumns {
+			continue
+		}
+		if dr.activeColumn > 0 && dr.activeColumn <= len(row) &&
+			slices.Contains(inactiveValues, strings.ToLower(strings.TrimSpace(row[dr.activeColumn-1]))) {
+			continue
+		}
+		rowIndexes = append(rowIndexes, rowIndex)
+	}
+	return rowIndexes
+}
+
+// listSheets は各シートのテーブル名とフィールド数を一覧表示する（XML は生成しない）。
+func listSheets(opts options) error {
+	rec, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+	book, err := openSheetReader(opts.workbookPath)
+	if err != nil {
+		return withExitCode(exitInput, err)
+	}
+	defer book.Close()
+
+	if rec, err = applyConfigSheet(book, rec); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	dr, err := rec.dataRange()
+	if err != nil {
+		return err
+	}
+	rules := rec.sheetFilter()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSHEET\tTABLE\tFIELDS\t")
+	for index, sheetName := range book.GetSheetList() {
+		tableName, _ := book.GetCellValue(sheetName, rec.BaseTable.Name)
+		rows, err := book.GetRows(sheetName)
+		if err != nil {
+			return withExitCode(exitInput, fmt.Errorf("%s: %w", sheetName, err))
+		}
+		status := ""
+		if !rules.selects(sheetName) || !opts.sheets.selects(sheetName) {
+			status = "skipped"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", index+1, sheetName, tableName, len(dataRowIndexes(rows, dr)), status)
+	}
+	return w.Flush()
+}
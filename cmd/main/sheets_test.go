@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSheetFilter(t *testing.T) {
+	sheets := []string{"SAMPLE", "@Sys", "#FUNCTIONS", "log_2024", "log_2025", "Memo"}
+	tests := []struct {
+		name   string
+		filter sheetFilter
+		want   []string
+	}{
+		{name: "all", want: sheets},
+		{name: "glob", filter: sheetFilter{include: []string{"log_*"}}, want: []string{"log_2024", "log_2025"}},
+		{name: "regexp", filter: sheetFilter{include: []string{"/^[A-Z]+$/"}}, want: []string{"SAMPLE"}},
+		{name: "exclude", filter: sheetFilter{exclude: []string{"log_*", "@*"}}, want: []string{"SAMPLE", "#FUNCTIONS", "Memo"}},
+		{
+			name:   "include and exclude",
+			filter: sheetFilter{include: []string{"log_*", "Memo"}, exclude: []string{"*2024"}},
+			want:   []string{"log_2025", "Memo"},
+		},
+		{name: "default rules", filter: defaultSheetRules.filter(), want: []string{"SAMPLE", "@Sys", "log_2024", "log_2025", "Memo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, sheetName := range sheets {
+				if tt.filter.selects(sheetName) {
+					got = append(got, sheetName)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := (sheetFilter{exclude: []string{"/(/"}}).validate(); err == nil {
+		t.Error("invalid regexp is accepted")
+	}
+}
+
+func TestDataRowIndexes(t *testing.T) {
+	rows := [][]string{
+		{"title"},
+		{"1", "", "id"},
+		{"2", "", "name"},
+		{"3"}, // 値が 1 列しかない行は空行
+		{"4", "", "memo", "無効"},
+		{"END"},
+		{"6", "", "after"},
+	}
+	tests := []struct {
+		name  string
+		rules rowRules
+		want  []int
+		err   string
+	}{
+		{name: "from the Field id row", want: []int{1, 2, 4, 6}},
+		{name: "start and end", rules: rowRules{Start: "3", End: "5"}, want: []int{2, 4}},
+		{name: "terminator", rules: rowRules{Terminator: "END"}, want: []int{1, 2, 4}},
+		{name: "active column", rules: rowRules{Active: "D2"}, want: []int{1, 2, 6}},
+		{name: "min columns", rules: rowRules{MinColumns: "1"}, want: []int{1, 2, 3, 4, 5, 6}},
+		{name: "invalid start", rules: rowRules{Start: "x"}, err: `Rows start: strconv.Atoi: parsing "x": invalid syntax`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := fmxmlSnippet{Rows: tt.rules}
+			rec.BaseTable.Field.ID = "A2"
+			dr, err := rec.dataRange()
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if got := dataRowIndexes(rows, dr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// renderSnippet は schema を FileMaker のテーブル定義の fmxmlsnippet に変換する。
+func renderSnippet(s *schema) *xmlquery.Node {
+	rootElement := &xmlquery.Node{
+		Data: "fmxmlsnippet",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{
+			{Name: xml.Name{Local: "type"}, Value: "FMObjectList"},
+		},
+	}
+	for _, t := range s.Tables {
+		tableElement := &xmlquery.Node{
+			Data: "BaseTable",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "name"}, Value: t.Name},
+			},
+		}
+		for _, f := range t.Fields {
+			xmlquery.AddChild(tableElement, renderField(f))
+		}
+		xmlquery.AddChild(rootElement, tableElement)
+	}
+	return rootElement
+}
+
+func renderTableSnippet(s *schema, _ outputOptions) ([]byte, error) {
+	return []byte(renderSnippet(s).OutputXML(true)), nil
+}
+
+func newElement(name string, attrs ...string) *xmlquery.Node {
+	element := &xmlquery.Node{Data: name, Type: xmlquery.ElementNode}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		element.Attr = append(element.Attr, xmlquery.Attr{Name: xml.Name{Local: attrs[i]}, Value: attrs[i+1]})
+	}
+	return element
+}
+
+func newTextElement(name, text string) *xmlquery.Node {
+	element := newElement(name)
+	xmlquery.AddChild(element, &xmlquery.Node{Data: text, Type: xmlquery.TextNode})
+	return element
+}
+
+func renderField(f *field) *xmlquery.Node {
+	fieldElement := &xmlquery.Node{
+		Data: "Field",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{
+			{Name: xml.Name{Local: "id"}, Value: f.ID},
+			{Name: xml.Name{Local: "name"}, Value: f.Name},
+			{Name: xml.Name{Local: "fieldType"}, Value: f.FieldType},
+			{Name: xml.Name{Local: "dataType"}, Value: f.DataType},
+		},
+	}
+
+	if f.FieldType == "Summary" {
+		summaryInfoElement := &xmlquery.Node{
+			Data: "SummaryInfo",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "restartForEachSortedGroup"}, Value: "False"},
+				{Name: xml.Name{Local: "summarizeRepetition"}, Value: f.Summary.Repetition},
+				{Name: xml.Name{Local: "operation"}, Value: f.Summary.Operation},
+			},
+		}
+		summaryFieldElement := &xmlquery.Node{Data: "SummaryField", Type: xmlquery.ElementNode}
+		xmlquery.AddChild(summaryFieldElement, &xmlquery.Node{
+			Data: "Field",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "id"}, Value: f.Summary.target.ID},
+				{Name: xml.Name{Local: "name"}, Value: f.Summary.target.Name},
+			},
+		})
+		xmlquery.AddChild(summaryInfoElement, summaryFieldElement)
+		xmlquery.AddChild(fieldElement, summaryInfoElement)
+	}
+
+	commentElement := &xmlquery.Node{Data: "Comment", Type: xmlquery.ElementNode}
+	xmlquery.AddChild(commentElement, &xmlquery.Node{
+		Data: f.Comment,
+		Type: xmlquery.TextNode,
+	})
+	xmlquery.AddChild(fieldElement, commentElement)
+
+	if f.FieldType == "Calculated" {
+		calc := f.Calculation
+		if calc == nil {
+			calc = &calculation{}
+		}
+		calcElement := &xmlquery.Node{
+			Data: "Calculation",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "table"}, Value: calc.Table},
+			},
+		}
+		xmlquery.AddChild(calcElement, &xmlquery.Node{
+			Data: calc.Text,
+			Type: xmlquery.TextNode,
+		})
+		xmlquery.AddChild(fieldElement, calcElement)
+	}
+
+	rules := rulesFor(f)
+	if rules.autoEnter {
+		xmlquery.AddChild(fieldElement, renderAutoEnter(f.AutoEnter))
+	}
+	if rules.validation {
+		v := f.Validation
+		if !rules.uniqueness {
+			v.Unique, v.Existing = "False", "False"
+		}
+		xmlquery.AddChild(fieldElement, renderValidation(v))
+	}
+	if rules.storage {
+		st := f.Storage
+		if !rules.index {
+			st.Index = "None"
+		}
+		xmlquery.AddChild(fieldElement, &xmlquery.Node{
+			Data: "Storage",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "autoIndex"}, Value: st.AutoIndex},
+				{Name: xml.Name{Local: "index"}, Value: st.Index},
+				{Name: xml.Name{Local: "indexLanguage"}, Value: st.IndexLanguage},
+				{Name: xml.Name{Local: "global"}, Value: st.Global},
+				{Name: xml.Name{Local: "maxRepetition"}, Value: st.MaxRepetition},
+			},
+		})
+	}
+	return fieldElement
+}
+
+func renderAutoEnter(ae autoEnter) *xmlquery.Node {
+	autoEnterElement := &xmlquery.Node{
+		Data: "AutoEnter",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{
+			{Name: xml.Name{Local: "constant"}, Value: "False"},
+			{Name: xml.Name{Local: "calculation"}, Value: "False"},
+			{Name: xml.Name{Local: "alwaysEvaluate"}, Value: ae.AlwaysEvaluate},
+			{Name: xml.Name{Local: "overwriteExistingValue"}, Value: ae.OverwriteExistingValue},
+			{Name: xml.Name{Local: "allowEditing"}, Value: ae.AllowEditing},
+			{Name: xml.Name{Local: "furigana"}, Value: ae.Furigana},
+			{Name: xml.Name{Local: "lookup"}, Value: ae.Lookup},
+		},
+	}
+
+	constantDataElement := &xmlquery.Node{Data: "ConstantData", Type: xmlquery.ElementNode}
+	text := ae.ConstantData
+	switch ae.Kind {
+	case "固定値":
+		autoEnterElement.SetAttr("constant", "True")
+	case "計算値":
+		autoEnterElement.SetAttr("calculation", "True")
+		calc := ae.Calculation
+		if calc == nil {
+			calc = &calculation{}
+		}
+		text = calc.Text
+		constantDataElement = &xmlquery.Node{
+			Data: "Calculation",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "table"}, Value: calc.Table},
+			},
+		}
+	case "シリアル番号":
+		s := ae.Serial
+		if s == nil {
+			s = &serial{}
+		}
+		xmlquery.AddChild(autoEnterElement, &xmlquery.Node{
+			Data: "Serial",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{
+				{Name: xml.Name{Local: "increment"}, Value: s.Increment},
+				{Name: xml.Name{Local: "nextValue"}, Value: s.NextValue},
+				{Name: xml.Name{Local: "generate"}, Value: s.Generate},
+			},
+		})
+		return autoEnterElement
+	default:
+		if value, ok := autoEnterValues[ae.Kind]; ok {
+			autoEnterElement.SetAttr("value", value)
+		}
+	}
+	xmlquery.AddChild(constantDataElement, &xmlquery.Node{
+		Data: text,
+		Type: xmlquery.TextNode,
+	})
+	xmlquery.AddChild(autoEnterElement, constantDataElement)
+	return autoEnterElement
+}
+
+func renderValidation(v validation) *xmlquery.Node {
+	// StrictDataType が設定されている場合、StrictValidation のデフォルトは True
+	strictValidationValue := v.StrictValidation
+	if v.StrictDataType != "" && strictValidationValue == "" {
+		strictValidationValue = "True"
+	}
+	if strings.EqualFold(strictValidationValue, "True") {
+		strictValidationValue = "True"
+	} else {
+		strictValidationValue = "False"
+	}
+
+	validationElement := &xmlquery.Node{
+		Data: "Validation",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{
+			{Name: xml.Name{Local: "maxLength"}, Value: map[bool]string{true: "True", false: "False"}[v.MaxDataLength != ""]},
+			{Name: xml.Name{Local: "message"}, Value: v.Message},
+			{Name: xml.Name{Local: "valuelist"}, Value: v.Valuelist},
+			{Name: xml.Name{Local: "calculation"}, Value: v.Calculation},
+			{Name: xml.Name{Local: "alwaysValidateCalculation"}, Value: v.AlwaysValidateCalculation},
+			{Name: xml.Name{Local: "type"}, Value: v.Type},
+		},
+	}
+
+	// 列順に追加: タイプ → ユニーク → 空欄不可 → 文字制限 → 既存値 → 上書き
+	if v.StrictDataType != "" {
+		xmlquery.AddChild(validationElement, &xmlquery.Node{
+			Data: "StrictDataType",
+			Type: xmlquery.ElementNode,
+			Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: v.StrictDataType}},
+		})
+	}
+	xmlquery.AddChild(validationElement, &xmlquery.Node{
+		Data: "Unique",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: v.Unique}},
+	})
+	xmlquery.AddChild(validationElement, &xmlquery.Node{
+		Data: "NotEmpty",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: v.NotEmpty}},
+	})
+	xmlquery.AddChild(validationElement, &xmlquery.Node{
+		Data: "MaxDataLength",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: v.MaxDataLength}},
+	})
+	xmlquery.AddChild(validationElement, &xmlquery.Node{
+		Data: "Existing",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: v.Existing}},
+	})
+	xmlquery.AddChild(validationElement, &xmlquery.Node{
+		Data: "StrictValidation",
+		Type: xmlquery.ElementNode,
+		Attr: []xmlquery.Attr{{Name: xml.Name{Local: "value"}, Value: strictValidationValue}},
+	})
+	return validationElement
+}
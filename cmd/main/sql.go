@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sqlDialect は SQL 出力の方言ごとの違い。
+type sqlDialect struct {
+	quote    func(name string) string
+	types    map[string]string // dataType → 列の型
+	varchar  string            // MaxDataLength があるテキストの型（%d に文字数）
+	identity string            // 数字型のシリアル番号の型
+	// keyedText は UNIQUE や DEFAULT を付ける長さのないテキストの型。
+	// MySQL の TEXT には長さなしのインデックス（UNIQUE）も DEFAULT（8.0.13 より前）も付けられない。
+	keyedText string
+}
+
+func quoteWith(open, close string) func(string) string {
+	return func(name string) string {
+		return open + strings.ReplaceAll(name, close, close+close) + close
+	}
+}
+
+var sqlDialects = map[string]sqlDialect{
+	"postgres": {
+		quote: quoteWith(`"`, `"`),
+		types: map[string]string{
+			"Text": "TEXT", "Number": "NUMERIC", "Date": "DATE", "Time": "TIME", "TimeStamp": "TIMESTAMP", "Binary": "BYTEA",
+		},
+		varchar:  "VARCHAR(%d)",
+		identity: "BIGINT GENERATED BY DEFAULT AS IDENTITY",
+	},
+	"mysql": {
+		quote: quoteWith("`", "`"),
+		types: map[string]string{
+			"Text": "TEXT", "Number": "DOUBLE", "Date": "DATE", "Time": "TIME", "TimeStamp": "DATETIME", "Binary": "LONGBLOB",
+		},
+		varchar:   "VARCHAR(%d)",
+		identity:  "BIGINT AUTO_INCREMENT UNIQUE",
+		keyedText: "VARCHAR(255)",
+	},
+	"sqlite": {
+		quote: quoteWith(`"`, `"`),
+		types: map[string]string{
+			"Text": "TEXT", "Number": "NUMERIC", "Date": "TEXT", "Time": "TEXT", "TimeStamp": "TEXT", "Binary": "BLOB",
+		},
+		varchar: "VARCHAR(%d)",
+		// SQLite の AUTOINCREMENT は rowid の主キーにしか使えない
+		identity: "INTEGER PRIMARY KEY AUTOINCREMENT",
+	},
+}
+
+func dialectNames() string {
+	var names []string
+	for name := range sqlDialects {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+var sqlCommentReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// sqlLiteral は固定値の自動入力を DEFAULT 句の値にする。
+func sqlLiteral(dataType, value string) string {
+	if dataType == "Number" {
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func isKeyedConstraint(c string) bool {
+	return c == "UNIQUE" || strings.HasPrefix(c, "DEFAULT ")
+}
+
+// renderSQL はテーブルごとの CREATE TABLE 文を返す。
+// 計算・集計・グローバルフィールドは行のデータを持たないので、列ではなくコメントとして残す。
+func renderSQL(s *schema, opts outputOptions) ([]byte, error) {
+	d, ok := sqlDialects[opts.dialect]
+	if !ok {
+		return nil, fmt.Errorf("unknown SQL dialect %q (%s)", opts.dialect, dialectNames())
+	}
+
+	var buf bytes.Buffer
+	for i, t := range s.Tables {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		type line struct {
+			column  string
+			comment string
+		}
+		var lines []line
+		hasIdentity := false
+		for _, f := range t.Fields {
+			switch {
+			case f.FieldType == "Calculated":
+				text := ""
+				if f.Calculation != nil {
+					text = " = " + f.Calculation.Text
+				}
+				lines = append(lines, line{comment: fmt.Sprintf("%s: calculated %s%s", f.Name, f.DataType, text)})
+				continue
+			case f.FieldType == "Summary":
+				lines = append(lines, line{comment: fmt.Sprintf("%s: summary %s(%s)", f.Name, f.Summary.Operation, f.Summary.target.Name)})
+				continue
+			case isTrue(f.Storage.Global):
+				lines = append(lines, line{comment: fmt.Sprintf("%s: global %s", f.Name, f.DataType)})
+				continue
+			}
+
+			columnType := d.types[f.DataType]
+			if columnType == "" {
+				columnType = d.types["Text"]
+			}
+			if n, err := strconv.Atoi(f.Validation.MaxDataLength); err == nil && f.DataType == "Text" {
+				columnType = fmt.Sprintf(d.varchar, n)
+			}
+			var constraints []string
+			isIdentity := f.AutoEnter.Kind == "シリアル番号" && f.DataType == "Number" && !hasIdentity
+			if isIdentity {
+				columnType = d.identity
+				hasIdentity = true
+			}
+			if isTrue(f.Validation.NotEmpty) {
+				constraints = append(constraints, "NOT NULL")
+			}
+			if isTrue(f.Validation.Unique) && !isIdentity {
+				constraints = append(constraints, "UNIQUE")
+			}
+			switch f.AutoEnter.Kind {
+			case "固定値":
+				if f.AutoEnter.ConstantData != "" {
+					constraints = append(constraints, "DEFAULT "+sqlLiteral(f.DataType, f.AutoEnter.ConstantData))
+				}
+			case "作成TS":
+				constraints = append(constraints, "DEFAULT CURRENT_TIMESTAMP")
+			case "作成日":
+				constraints = append(constraints, "DEFAULT CURRENT_DATE")
+			case "作成時刻":
+				constraints = append(constraints, "DEFAULT CURRENT_TIME")
+			}
+
+			if d.keyedText != "" && columnType == d.types["Text"] && slices.ContainsFunc(constraints, isKeyedConstraint) {
+				columnType = d.keyedText
+			}
+
+			column := strings.Join(append([]string{d.quote(f.Name), columnType}, constraints...), " ")
+			comment := f.Comment
+			if n, _ := strconv.Atoi(f.Storage.MaxRepetition); n > 1 {
+				comment = strings.TrimSpace(fmt.Sprintf("repetitions: %d %s", n, comment))
+			}
+			lines = append(lines, line{column: column, comment: comment})
+		}
+
+		last := -1
+		for i, l := range lines {
+			if l.column != "" {
+				last = i
+			}
+		}
+		// 列が 1 つもない CREATE TABLE は構文エラーになるので、テーブルごとコメントにする
+		if last < 0 {
+			fmt.Fprintf(&buf, "-- %s: no columns (only calculated, summary or global fields)\n", d.quote(t.Name))
+			for _, l := range lines {
+				buf.WriteString("--   " + sqlCommentReplacer.Replace(l.comment) + "\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&buf, "CREATE TABLE %s (\n", d.quote(t.Name))
+		for i, l := range lines {
+			buf.WriteString("  ")
+			if l.column != "" {
+				buf.WriteString(l.column)
+				if i != last {
+					buf.WriteString(",")
+				}
+				if l.comment != "" {
+					buf.WriteString(" ")
+				}
+			}
+			if l.comment != "" {
+				buf.WriteString("-- " + sqlCommentReplacer.Replace(l.comment))
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(");\n")
+	}
+	return buf.Bytes(), nil
+}
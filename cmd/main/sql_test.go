@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSQLTextColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		field   field
+		column  string
+	}{
+		{
+			name:    "mysql plain text",
+			dialect: "mysql",
+			field:   field{Name: "memo", DataType: "Text"},
+			column:  "`memo` TEXT",
+		},
+		{
+			name:    "mysql not empty text",
+			dialect: "mysql",
+			field:   field{Name: "memo", DataType: "Text", Validation: validation{NotEmpty: "True"}},
+			column:  "`memo` TEXT NOT NULL",
+		},
+		{
+			name:    "mysql unique text",
+			dialect: "mysql",
+			field:   field{Name: "code", DataType: "Text", Validation: validation{Unique: "True"}},
+			column:  "`code` VARCHAR(255) UNIQUE",
+		},
+		{
+			name:    "mysql text with default",
+			dialect: "mysql",
+			field:   field{Name: "status", DataType: "Text", AutoEnter: autoEnter{Kind: "固定値", ConstantData: "new"}},
+			column:  "`status` VARCHAR(255) DEFAULT 'new'",
+		},
+		{
+			name:    "mysql unique text with max length",
+			dialect: "mysql",
+			field:   field{Name: "code", DataType: "Text", Validation: validation{Unique: "True", MaxDataLength: "10"}},
+			column:  "`code` VARCHAR(10) UNIQUE",
+		},
+		{
+			name:    "mysql unique number",
+			dialect: "mysql",
+			field:   field{Name: "no", DataType: "Number", Validation: validation{Unique: "True"}},
+			column:  "`no` DOUBLE UNIQUE",
+		},
+		{
+			name:    "postgres unique text",
+			dialect: "postgres",
+			field:   field{Name: "code", DataType: "Text", Validation: validation{Unique: "True"}},
+			column:  `"code" TEXT UNIQUE`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.field
+			f.FieldType = "Normal"
+			f.setDefaults()
+			s := &schema{Tables: []*baseTable{{Name: "t", Fields: []*field{&f}}}}
+			out, err := renderSQL(s, outputOptions{dialect: tt.dialect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), "\n  "+tt.column+"\n") {
+				t.Errorf("column %q not found in\n%s", tt.column, out)
+			}
+		})
+	}
+}
+
+func TestRenderSQLNoColumns(t *testing.T) {
+	calculated := &field{Name: "total", FieldType: "Calculated", DataType: "Number", Calculation: &calculation{Text: "a + b"}}
+	global := &field{Name: "g", FieldType: "Normal", DataType: "Text", Storage: storage{Global: "True"}}
+	memo := &field{Name: "memo", FieldType: "Normal", DataType: "Text"}
+	tests := []struct {
+		name   string
+		tables []*baseTable
+		want   string
+	}{
+		{
+			name:   "no columns",
+			tables: []*baseTable{{Name: "t", Fields: []*field{calculated, global}}},
+			want: `-- "t": no columns (only calculated, summary or global fields)
+--   total: calculated Number = a + b
+--   g: global Text
+`,
+		},
+		{
+			name:   "no fields",
+			tables: []*baseTable{{Name: "t"}},
+			want: `-- "t": no columns (only calculated, summary or global fields)
+`,
+		},
+		{
+			name: "with another table",
+			tables: []*baseTable{
+				{Name: "t", Fields: []*field{global}},
+				{Name: "u", Fields: []*field{memo, calculated}},
+			},
+			want: `-- "t": no columns (only calculated, summary or global fields)
+--   g: global Text
+
+CREATE TABLE "u" (
+  "memo" TEXT
+  -- total: calculated Number = a + b
+);
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderSQL(&schema{Tables: tt.tables}, outputOptions{dialect: "postgres"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
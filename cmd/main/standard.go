@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	standardPrepend = "prepend"
+	standardAppend  = "append"
+)
+
+// standardFields はすべての BaseTable に追加する標準フィールド（UUID の主キーや作成・修正情報など）。
+type standardFields struct {
+	Position string   `json:"position,omitempty" yaml:"position,omitempty"` // prepend（既定）/ append
+	Fields   []*field `json:"fields" yaml:"fields"`
+}
+
+// standardRules は config.xml の <StandardFields>。標準フィールドを定義するシートと追加位置、追加しないシート。
+type standardRules struct {
+	Sheet    string           `xml:"sheet,attr"`
+	Position string           `xml:"position,attr"`
+	Exclude  []patternElement `xml:"Exclude"`
+}
+
+var defaultStandardRules = standardRules{Sheet: "#STANDARD"}
+
+func (rec fmxmlSnippet) standardRules() standardRules {
+	if rec.StandardFields == nil {
+		return defaultStandardRules
+	}
+	rules := *rec.StandardFields
+	if rules.Sheet == "" {
+		rules.Sheet = defaultStandardRules.Sheet
+	}
+	return rules
+}
+
+// optsOut は標準フィールドを追加しないシートかどうかを返す。
+func (rules standardRules) optsOut(sheetName string) bool {
+	for _, e := range rules.Exclude {
+		if ok, _ := matchPattern(e.Pattern, sheetName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStandardFields は標準フィールドのシート（デフォルトは #STANDARD）を、テーブルのシートと同じ列割り当てで読み込む。
+func parseStandardFields(book sheetReader, rec fmxmlSnippet, dr dataRange) (*standardFields, error) {
+	rules := rec.standardRules()
+	if !slices.Contains(book.GetSheetList(), rules.Sheet) {
+		return nil, nil
+	}
+	rows, err := book.GetRows(rules.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	std := &standardFields{Position: rules.Position}
+	for _, rowIndex := range dataRowIndexes(rows, dr) {
+		f := parseField(book, rec, rules.Sheet, rowIndex)
+		// 行番号の ID はテーブルのシートの ID と重なるので使わない（assignFieldIDs で最大値の次から採番する）
+		f.defaultID = ""
+		std.Fields = append(std.Fields, f)
+	}
+	return std, nil
+}
+
+// clone はテーブルごとに独立したフィールドのコピーを返す。
+func (f *field) clone() *field {
+	c := *f
+	if f.Calculation != nil {
+		calc := *f.Calculation
+		c.Calculation = &calc
+	}
+	if f.Summary != nil {
+		summary := *f.Summary
+		c.Summary = &summary
+	}
+	if f.AutoEnter.Calculation != nil {
+		calc := *f.AutoEnter.Calculation
+		c.AutoEnter.Calculation = &calc
+	}
+	if f.AutoEnter.Serial != nil {
+		serial := *f.AutoEnter.Serial
+		c.AutoEnter.Serial = &serial
+	}
+	c.Validation.Values = slices.Clone(f.Validation.Values)
+	c.Access = slices.Clone(f.Access)
+	return &c
+}
+
+// injectStandardFields は標準フィールドを各 BaseTable の先頭または末尾に追加する。
+// シートで同じ名前のフィールドを定義している場合はエラーにする（FileMaker と同じく大文字小文字は区別しない）。
+func injectStandardFields(s *schema) error {
+	std := s.Standard
+	if std == nil || len(std.Fields) == 0 {
+		return nil
+	}
+	if std.Position != "" && std.Position != standardPrepend && std.Position != standardAppend {
+		return fmt.Errorf("unknown standard field position %q (%s or %s)", std.Position, standardPrepend, standardAppend)
+	}
+	for _, t := range s.Tables {
+		if t.NoStandardFields {
+			continue
+		}
+		standard := std.Fields
+		if t.standard != nil {
+			standard = t.standard
+		}
+		var fields []*field
+		for _, sf := range standard {
+			if i := slices.IndexFunc(t.Fields, func(f *field) bool { return strings.EqualFold(f.Name, sf.Name) }); i >= 0 {
+				return fmt.Errorf("%s: field %q collides with the standard field defined in %s", t.Fields[i].origin, t.Fields[i].Name, sf.origin)
+			}
+			fields = append(fields, sf.clone())
+		}
+		if std.Position == standardAppend {
+			t.Fields = append(t.Fields, fields...)
+		} else {
+			t.Fields = append(fields, t.Fields...)
+		}
+	}
+	// 追加済みなので、convert で書き出したときに二重に追加されないようにする
+	s.Standard = nil
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestInjectStandardFieldsSheetDefaults(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = rec.withDefault(defaultRule{Property: "storage.indexLanguage", Value: "Japanese", Cell: "K5"})
+
+	tests := []struct {
+		name          string
+		k5            string
+		indexLanguage string
+	}{
+		{name: "sheet default", k5: "English", indexLanguage: "English"},
+		{name: "value", k5: "", indexLanguage: "Japanese"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([][]string, 10)
+			rows[2] = testRow(t, map[string]string{"K": "t"})
+			rows[4] = testRow(t, map[string]string{"K": tt.k5})
+			rows[9] = testRow(t, map[string]string{"C": "name"})
+			book := newGridReader()
+			book.add("t", rows)
+			standard := make([][]string, 10)
+			standard[9] = testRow(t, map[string]string{"C": "uuid"})
+			book.add(defaultStandardRules.Sheet, standard)
+
+			s, err := parseWorkbook(book, rec, sheetFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := injectStandardFields(s); err != nil {
+				t.Fatal(err)
+			}
+			fields := s.Tables[0].Fields
+			if len(fields) != 2 || fields[0].Name != "uuid" {
+				t.Fatalf("fields = %v, want the standard field uuid first", fields)
+			}
+			for _, f := range fields {
+				if f.Storage.IndexLanguage != tt.indexLanguage {
+					t.Errorf("%s: indexLanguage = %q, want %q", f.Name, f.Storage.IndexLanguage, tt.indexLanguage)
+				}
+			}
+		})
+	}
+}
+
+func TestInjectStandardFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  standardRules
+		sheets map[string][]string // シート名 → フィールド名
+		want   map[string][]string // テーブル名 → "ID:フィールド名"
+		err    string
+	}{
+		{
+			name:   "prepend with IDs after the sheet fields",
+			sheets: map[string][]string{"a": {"memo", "note"}},
+			want:   map[string][]string{"a": {"11:uuid", "12:created", "9:memo", "10:note"}},
+		},
+		{
+			name:   "append",
+			rules:  standardRules{Position: standardAppend},
+			sheets: map[string][]string{"a": {"memo"}},
+			want:   map[string][]string{"a": {"9:memo", "10:uuid", "11:created"}},
+		},
+		{
+			name:   "excluded sheet",
+			rules:  standardRules{Exclude: []patternElement{{Pattern: "log*"}}},
+			sheets: map[string][]string{"a": {"memo"}, "log": {"message"}},
+			want:   map[string][]string{"a": {"10:uuid", "11:created", "9:memo"}, "log": {"9:message"}},
+		},
+		{
+			name:   "collision",
+			sheets: map[string][]string{"a": {"memo", "uuid"}},
+			err:    `a!A11: field "uuid" collides with the standard field defined in #STANDARD!A10`,
+		},
+		{
+			name:   "collision ignores case",
+			sheets: map[string][]string{"a": {"Created"}},
+			err:    `a!A10: field "Created" collides with the standard field defined in #STANDARD!A11`,
+		},
+		{
+			name:   "unknown position",
+			rules:  standardRules{Position: "middle"},
+			sheets: map[string][]string{"a": {"memo"}},
+			err:    `unknown standard field position "middle" (prepend or append)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := readConfig("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec.StandardFields = &tt.rules
+
+			book := newGridReader()
+			for _, sheetName := range slices.Sorted(maps.Keys(tt.sheets)) {
+				var fields []map[string]string
+				for _, name := range tt.sheets[sheetName] {
+					fields = append(fields, map[string]string{"C": name})
+				}
+				rows := testRows(t, fields...)
+				rows[2] = testRow(t, map[string]string{"K": sheetName})
+				book.add(sheetName, rows)
+			}
+			book.add(defaultStandardRules.Sheet, testRows(t, map[string]string{"C": "uuid"}, map[string]string{"C": "created"}))
+
+			s, err := parseWorkbook(book, rec, sheetFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = prepareSchema(s, idStrategyExplicit, nil)
+			if (err == nil) != (tt.err == "") || err != nil && err.Error() != tt.err {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			got := map[string][]string{}
+			for _, table := range s.Tables {
+				for _, f := range table.Fields {
+					got[table.Name] = append(got[table.Name], f.ID+":"+f.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %q, want %q", got, tt.want)
+			}
+			if s.Standard != nil {
+				t.Error("standard fields are left in the schema after injection")
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+)
+
+type fileStamp struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// stampFiles はファイルの更新日時とサイズを返す。CSV/TSV のディレクトリは中の *.csv / *.tsv を見る
+// （ディレクトリ自体の更新日時は、ファイルを書き換えても変わらない）。
+func stampFiles(paths ...string) []fileStamp {
+	var stamps []fileStamp
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			files, _ := csvDirFiles(path)
+			stamps = append(stamps, stampFiles(files...)...)
+			continue
+		}
+		stamp := fileStamp{path: path}
+		// 保存中でファイルが一時的に存在しない場合は更新日時とサイズがゼロ値のまま扱う
+		if err == nil {
+			stamp.modTime, stamp.size = info.ModTime(), info.Size()
+		}
+		stamps = append(stamps, stamp)
+	}
+	return stamps
+}
+
+// watchFiles はワークブックと config.xml の更新を監視し、変更のたびに再生成して publish に渡す。
+// Excel は一時ファイル経由で保存するため、変更を検出してから 1 周期変化がなくなるのを待って生成する。
+func watchFiles(opts options, interval time.Duration, publish func(s *schema) error) {
+	var prev []tableSnapshot
+	var last []fileStamp
+	pending := true
+	fmt.Printf("watching %s and %s\n", opts.workbookPath, configName(opts.configPath))
+	for ; ; time.Sleep(interval) {
+		current := stampFiles(opts.workbookPath, opts.configPath)
+		if !slices.Equal(current, last) {
+			last = current
+			pending = true
+			continue
+		}
+		if !pending {
+			continue
+		}
+		pending = false
+
+		now := time.Now().Format("15:04:05")
+		s, err := loadSchema(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s error: %v\n", now, err)
+			continue
+		}
+		if err = publish(s); err != nil {
+			fmt.Fprintf(os.Stderr, "%s error: %v\n", now, err)
+			continue
+		}
+		snapshot := takeSnapshot(renderSnippet(s))
+		if prev == nil {
+			fields := 0
+			for _, t := range snapshot {
+				fields += len(t.fields)
+			}
+			fmt.Printf("%s generated: %d tables, %d fields\n", now, len(snapshot), fields)
+		} else if changes := diffSnapshots(prev, snapshot); len(changes) == 0 {
+			fmt.Printf("%s regenerated: no changes\n", now)
+		} else {
+			fmt.Printf("%s regenerated: %s\n", now, strings.Join(changes, "; "))
+		}
+		prev = snapshot
+	}
+}
+
+// tableSnapshot は変更点の要約に使う、生成済み BaseTable 1 つ分の内容。
+type tableSnapshot struct {
+	name   string
+	fields []string          // 定義順のフィールド名
+	defs   map[string]string // フィールド名 → Field 要素の XML
+}
+
+func takeSnapshot(rootElement *xmlquery.Node) []tableSnapshot {
+	var snapshot []tableSnapshot
+	for _, tableElement := range xmlquery.Find(rootElement, "BaseTable") {
+		t := tableSnapshot{name: tableElement.SelectAttr("name"), defs: map[string]string{}}
+		for _, fieldElement := range xmlquery.Find(tableElement, "Field") {
+			name := fieldElement.SelectAttr("name")
+			t.fields = append(t.fields, name)
+			t.defs[name] = fieldElement.OutputXML(true)
+		}
+		snapshot = append(snapshot, t)
+	}
+	return snapshot
+}
+
+func findTable(snapshot []tableSnapshot, name string) (tableSnapshot, bool) {
+	for _, t := range snapshot {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return tableSnapshot{}, false
+}
+
+// diffSnapshots は前回の生成結果からの変更点を "テーブル: +追加 -削除 ~変更" の形で返す。
+func diffSnapshots(prev, current []tableSnapshot) []string {
+	var changes []string
+	for _, t := range current {
+		old, ok := findTable(prev, t.name)
+		if !ok {
+			changes = append(changes, fmt.Sprintf("+%s (%d fields)", t.name, len(t.fields)))
+			continue
+		}
+		var parts []string
+		for _, name := range t.fields {
+			def, ok := old.defs[name]
+			switch {
+			case !ok:
+				parts = append(parts, "+"+name)
+			case def != t.defs[name]:
+				parts = append(parts, "~"+name)
+			}
+		}
+		for _, name := range old.fields {
+			if _, ok := t.defs[name]; !ok {
+				parts = append(parts, "-"+name)
+			}
+		}
+		if len(parts) > 0 {
+			changes = append(changes, t.name+": "+strings.Join(parts, " "))
+		}
+	}
+	for _, t := range prev {
+		if _, ok := findTable(current, t.name); !ok {
+			changes = append(changes, "-"+t.name)
+		}
+	}
+	return changes
+}
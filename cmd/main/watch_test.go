@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStampFilesCSVDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(dir string) error
+		same   bool
+	}{
+		{
+			name:   "unchanged",
+			change: func(string) error { return nil },
+			same:   true,
+		},
+		{
+			name: "file modified",
+			change: func(dir string) error {
+				path := filepath.Join(dir, "a.csv")
+				if err := os.WriteFile(path, []byte("a,b\n"), 0o644); err != nil {
+					return err
+				}
+				later := time.Now().Add(time.Minute)
+				return os.Chtimes(path, later, later)
+			},
+		},
+		{
+			name:   "file added",
+			change: func(dir string) error { return os.WriteFile(filepath.Join(dir, "c.tsv"), []byte("c\n"), 0o644) },
+		},
+		{
+			name:   "file removed",
+			change: func(dir string) error { return os.Remove(filepath.Join(dir, "b.tsv")) },
+		},
+		{
+			name:   "other file added",
+			change: func(dir string) error { return os.WriteFile(filepath.Join(dir, "memo.txt"), []byte("memo\n"), 0o644) },
+			same:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"a.csv", "b.tsv"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("a\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := stampFiles(dir, "")
+			if err := tt.change(dir); err != nil {
+				t.Fatal(err)
+			}
+			if same := slices.Equal(before, stampFiles(dir, "")); same != tt.same {
+				t.Errorf("unchanged = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	snapshot := func(name string, fields ...string) tableSnapshot {
+		t := tableSnapshot{name: name, defs: map[string]string{}}
+		for _, f := range fields {
+			// "名前=定義" の定義が変わるとフィールドの変更になる
+			name, def, _ := strings.Cut(f, "=")
+			t.fields = append(t.fields, name)
+			t.defs[name] = def
+		}
+		return t
+	}
+	tests := []struct {
+		name    string
+		prev    []tableSnapshot
+		current []tableSnapshot
+		want    []string
+	}{
+		{
+			name:    "no changes",
+			prev:    []tableSnapshot{snapshot("a", "id=1", "name=1")},
+			current: []tableSnapshot{snapshot("a", "id=1", "name=1")},
+		},
+		{
+			name:    "fields added, changed and removed",
+			prev:    []tableSnapshot{snapshot("a", "id=1", "name=1", "memo=1")},
+			current: []tableSnapshot{snapshot("a", "id=1", "name=2", "created=1")},
+			want:    []string{"a: ~name +created -memo"},
+		},
+		{
+			name:    "tables added and removed",
+			prev:    []tableSnapshot{snapshot("a", "id=1"), snapshot("b", "id=1")},
+			current: []tableSnapshot{snapshot("a", "id=1"), snapshot("c", "id=1", "name=1")},
+			want:    []string{"+c (2 fields)", "-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffSnapshots(tt.prev, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
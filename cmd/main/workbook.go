@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseWorkbook は config.xml の列割り当てに従ってワークブックの各シートを読み込む。
+func parseWorkbook(book sheetReader, rec fmxmlSnippet, sheets sheetFilter) (*schema, error) {
+	dr, err := rec.dataRange()
+	if err != nil {
+		return nil, err
+	}
+	rules := rec.sheetFilter()
+	if err = rules.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &schema{}
+	for index, sheetName := range book.GetSheetList() {
+		if !rules.selects(sheetName) || !sheets.selects(sheetName) {
+			continue
+		}
+		fmt.Println(index+1, sheetName)
+		rows, err := book.GetRows(sheetName)
+		if err != nil {
+			warn("%s: %v", sheetName, err)
+			continue
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		tableName, _ := book.GetCellValue(sheetName, rec.BaseTable.Name)
+		t := &baseTable{Name: tableName, NoStandardFields: rec.standardRules().optsOut(sheetName)}
+		sheetRec := rec.withDefaults(book, sheetName)
+		// trailing empty cells are stripped per row, so row lengths may differ — read cell values directly from sheet
+		if t.Fields, err = parseSheetFields(book, sheetRec, dr, sheetName, rows, nil); err != nil {
+			return nil, err
+		}
+		// 標準フィールドの空のセルにも、このシートの既定値を使う
+		if !t.NoStandardFields {
+			std, err := parseStandardFields(book, sheetRec, dr)
+			if err != nil {
+				return nil, err
+			}
+			if std != nil {
+				t.standard = std.Fields
+			}
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	if err = parseScriptRows(book, rec, s.Tables, len(sheets.include) > 0 || len(sheets.exclude) > 0); err != nil {
+		return nil, err
+	}
+	if s.Functions, err = parseFunctions(book, rec); err != nil {
+		return nil, err
+	}
+	if s.Standard, err = parseStandardFields(book, rec.withDefaults(book, ""), dr); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// cellOrigin はエラー表示用のセル位置（"シート!セル"）を返す。
+func cellOrigin(sheetName, cellName string, rowIndex int) string {
+	if cellName == "" {
+		return fmt.Sprintf("%s!%d", sheetName, rowIndex+1)
+	}
+	return sheetName + "!" + rowCell(cellName, rowIndex)
+}
+
+func parseField(book sheetReader, rec fmxmlSnippet, sheetName string, rowIndex int) *field {
+	fieldXML := rec.BaseTable.Field
+	// 列のセルが空のときの値（config.xml の <Defaults>。テーブルのシートごとに上書きできる）
+	def := rec.defaults
+	cell := func(cellName, defaultValue string) string {
+		return returnCellValue(book, sheetName, rowIndex, cellName, defaultValue)
+	}
+
+	f := &field{
+		ID:         cell(fieldXML.ID, def.ID),
+		Name:       cell(fieldXML.Name, fmt.Sprintf("Field#%d", rowIndex)),
+		FieldType:  cell(fieldXML.FieldType, def.FieldType),
+		DataType:   cell(fieldXML.DataType, def.DataType),
+		Comment:    cell(fieldXML.Comment, def.Comment),
+		origin:     cellOrigin(sheetName, fieldXML.ID, rowIndex),
+		nameOrigin: cellOrigin(sheetName, fieldXML.Name, rowIndex),
+		defaultID:  strconv.Itoa(rowIndex),
+	}
+
+	switch f.FieldType {
+	case "Summary":
+		// K列: "Together.Total" など summarizeRepetition.operation 形式
+		parts := strings.SplitN(f.DataType, ".", 2)
+		f.Summary = &summaryInfo{Repetition: "Together"}
+		if len(parts) == 2 {
+			f.Summary.Repetition, f.Summary.Operation = parts[0], parts[1]
+		}
+		// Q列: 集計対象フィールドの名前（"テーブル::名前" や従来の "id.name" も可）
+		f.Summary.Field = cell(fieldXML.Calculation.Value, def.Calculation.Value)
+		f.Summary.origin = cellOrigin(sheetName, fieldXML.Calculation.Value, rowIndex)
+		f.DataType = ""
+	case "Calculated":
+		f.Calculation = &calculation{
+			Table: cell(fieldXML.Calculation.Table, def.Calculation.Table),
+			Text:  cell(fieldXML.Calculation.Value, def.Calculation.Value),
+		}
+	}
+
+	autoEnterXML := fieldXML.AutoEnter
+	f.AutoEnter = autoEnter{
+		Kind:                   cell(autoEnterXML.Constant, def.AutoEnter.Constant),
+		AlwaysEvaluate:         cell(autoEnterXML.AlwaysEvaluate, def.AutoEnter.AlwaysEvaluate),
+		OverwriteExistingValue: cell(autoEnterXML.OverwriteExistingValue, def.AutoEnter.OverwriteExistingValue),
+		AllowEditing:           cell(autoEnterXML.AllowEditing, def.AutoEnter.AllowEditing),
+		Furigana:               cell(autoEnterXML.Furigana, def.AutoEnter.Furigana),
+		Lookup:                 cell(autoEnterXML.Lookup, def.AutoEnter.Lookup),
+	}
+	f.AutoEnter.normalizeKind()
+	switch f.AutoEnter.Kind {
+	case "計算値":
+		f.AutoEnter.Calculation = &calculation{
+			Table: cell(autoEnterXML.AutoCalcElement.Table, def.AutoEnter.AutoCalcElement.Table),
+			Text:  cell(autoEnterXML.AutoCalcElement.Value, def.AutoEnter.AutoCalcElement.Value),
+		}
+	case "シリアル番号":
+		f.AutoEnter.Serial = &serial{
+			Increment: autoEnterXML.Serial.Increment,
+			NextValue: cell(autoEnterXML.Serial.NextValue, def.AutoEnter.Serial.NextValue),
+			Generate:  autoEnterXML.Serial.Generate,
+		}
+	default:
+		f.AutoEnter.ConstantData = cell(autoEnterXML.ConstantData, def.AutoEnter.ConstantData)
+	}
+
+	validationXML := fieldXML.Validation
+	f.Validation = validation{
+		Type:                      cell(validationXML.Type, def.Validation.Type),
+		Message:                   cell(validationXML.Message, def.Validation.Message),
+		Valuelist:                 cell(validationXML.Valuelist, def.Validation.Valuelist),
+		Calculation:               cell(validationXML.Calculation, def.Validation.Calculation),
+		AlwaysValidateCalculation: cell(validationXML.AlwaysValidateCalculation, def.Validation.AlwaysValidateCalculation),
+		StrictDataType:            cell(validationXML.StrictDataType.Value, def.Validation.StrictDataType.Value),
+		Unique:                    cell(validationXML.Unique.Value, def.Validation.Unique.Value),
+		NotEmpty:                  cell(validationXML.NotEmpty.Value, def.Validation.NotEmpty.Value),
+		MaxDataLength:             cell(validationXML.MaxDataLength.Value, def.Validation.MaxDataLength.Value),
+		Existing:                  cell(validationXML.Existing.Value, def.Validation.Existing.Value),
+		StrictValidation:          cell(validationXML.StrictValidation.Value, def.Validation.StrictValidation.Value),
+		Values:                    splitValues(cell(validationXML.Values.Value, def.Validation.Values.Value)),
+	}
+
+	storageXML := fieldXML.Storage
+	f.Storage = storage{
+		AutoIndex:     cell(storageXML.AutoIndex, def.Storage.AutoIndex),
+		Index:         cell(storageXML.Index, def.Storage.Index),
+		IndexLanguage: cell(storageXML.IndexLanguage, def.Storage.IndexLanguage),
+		Global:        cell(storageXML.Global, def.Storage.Global),
+		MaxRepetition: cell(storageXML.MaxRepetition, def.Storage.MaxRepetition),
+	}
+
+	for _, accessXML := range fieldXML.Access {
+		if value := cell(accessXML.Value, ""); value != "" {
+			f.Access = append(f.Access, fieldAccess{PrivilegeSet: accessXML.PrivilegeSet, Access: value})
+		}
+	}
+
+	f.setDefaults()
+	return f
+}
+
+// splitValues は値一覧のセル（改行またはカンマ区切り）を値ごとに分ける。
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == '\r' || r == ',' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
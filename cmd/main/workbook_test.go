@@ -0,0 +1,153 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// testRow は列名 → 値で指定した行を返す。
+func testRow(t *testing.T, cells map[string]string) []string {
+	t.Helper()
+	var row []string
+	for col, value := range cells {
+		n, err := excelize.ColumnNameToNumber(col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for len(row) < n {
+			row = append(row, "")
+		}
+		row[n-1] = value
+	}
+	return row
+}
+
+// testRows は 10 行目から、列名 → 値で指定したフィールドの行を並べたシートの行を返す。
+func testRows(t *testing.T, fields ...map[string]string) [][]string {
+	t.Helper()
+	rows := make([][]string, 9, 9+len(fields))
+	for _, cells := range fields {
+		rows = append(rows, testRow(t, cells))
+	}
+	return rows
+}
+
+// testBook は 1 枚のシートの 10 行目に、列名 → 値で指定したフィールドの行を持つ読み込み元を返す。
+func testBook(t *testing.T, sheetName string, cells map[string]string) *gridReader {
+	t.Helper()
+	book := newGridReader()
+	book.add(sheetName, testRows(t, cells))
+	return book
+}
+
+func TestParseFieldAutoEnterKind(t *testing.T) {
+	rec, err := readConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		cells        map[string]string
+		kind         string
+		allowEditing string
+		serial       *serial
+		calculation  *calculation
+		constantData string
+	}{
+		{
+			name:         "serial",
+			cells:        map[string]string{"C": "id", "AL": "シリアル番号", "AR": "100"},
+			kind:         "シリアル番号",
+			allowEditing: "True",
+			serial:       &serial{Increment: "1", NextValue: "100", Generate: "OnCreation"},
+		},
+		{
+			name:         "serial with suffix",
+			cells:        map[string]string{"C": "id", "AL": "シリアル番号+変更禁止", "AR": "100"},
+			kind:         "シリアル番号",
+			allowEditing: "False",
+			serial:       &serial{Increment: "1", NextValue: "100", Generate: "OnCreation"},
+		},
+		{
+			name:         "calculated with suffix",
+			cells:        map[string]string{"C": "total", "AL": "計算値（変更禁止）", "AO": "t", "AR": "a + b"},
+			kind:         "計算値",
+			allowEditing: "False",
+			calculation:  &calculation{Table: "t", Text: "a + b"},
+		},
+		{
+			name:         "creation date with suffix",
+			cells:        map[string]string{"C": "created", "AL": "作成日・変更禁止"},
+			kind:         "作成日",
+			allowEditing: "False",
+		},
+		{
+			name:         "constant",
+			cells:        map[string]string{"C": "status", "AL": "固定値", "AR": "new"},
+			kind:         "固定値",
+			allowEditing: "True",
+			constantData: "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := testBook(t, "t", tt.cells)
+			f := parseField(book, rec.withDefaults(book, "t"), "t", 9)
+			ae := f.AutoEnter
+			if ae.Kind != tt.kind || ae.AllowEditing != tt.allowEditing || ae.ConstantData != tt.constantData {
+				t.Errorf("kind, allowEditing, constantData = %q, %q, %q, want %q, %q, %q",
+					ae.Kind, ae.AllowEditing, ae.ConstantData, tt.kind, tt.allowEditing, tt.constantData)
+			}
+			if (ae.Serial == nil) != (tt.serial == nil) || ae.Serial != nil && *ae.Serial != *tt.serial {
+				t.Errorf("serial = %+v, want %+v", ae.Serial, tt.serial)
+			}
+			if (ae.Calculation == nil) != (tt.calculation == nil) || ae.Calculation != nil && *ae.Calculation != *tt.calculation {
+				t.Errorf("calculation = %+v, want %+v", ae.Calculation, tt.calculation)
+			}
+		})
+	}
+}
+
+func TestRenderAutoEnter(t *testing.T) {
+	tests := []struct {
+		name  string
+		ae    autoEnter
+		attrs map[string]string
+		child string
+	}{
+		{
+			name:  "serial",
+			ae:    autoEnter{Kind: "シリアル番号+変更禁止", Serial: &serial{Increment: "1", NextValue: "100", Generate: "OnCreation"}},
+			attrs: map[string]string{"allowEditing": "False"},
+			child: "Serial",
+		},
+		{
+			name:  "calculated",
+			ae:    autoEnter{Kind: "計算値", Calculation: &calculation{Text: "a + b"}},
+			attrs: map[string]string{"calculation": "True"},
+			child: "Calculation",
+		},
+		{
+			name:  "creation date",
+			ae:    autoEnter{Kind: "作成日+変更禁止"},
+			attrs: map[string]string{"value": "CreationDate", "allowEditing": "False"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &field{Name: "f", AutoEnter: tt.ae}
+			f.setDefaults()
+			node := renderAutoEnter(f.AutoEnter)
+			for name, want := range tt.attrs {
+				if got := node.SelectAttr(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if tt.child != "" && node.SelectElement(tt.child) == nil {
+				t.Errorf("<%s> not rendered", tt.child)
+			}
+		})
+	}
+}